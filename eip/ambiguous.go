@@ -0,0 +1,39 @@
+package eip
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// AmbiguousMatchError is returned when a lookup that must identify a single
+// resource matches several, instead of silently picking the first.
+type AmbiguousMatchError struct {
+	// What describes the matched resources, e.g. "network interfaces holding
+	// IPv6 2001:db8::1".
+	What string
+	// Candidates lists the IDs of the matching resources in sorted order.
+	Candidates []string
+}
+
+func newAmbiguousMatchError(what string, candidates []string) *AmbiguousMatchError {
+	candidates = slices.Clone(candidates)
+	slices.Sort(candidates)
+	return &AmbiguousMatchError{What: what, Candidates: candidates}
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	return fmt.Sprintf("%d %s: %s", len(e.Candidates), e.What, strings.Join(e.Candidates, ", "))
+}
+
+func networkInterfaceIDs(enis []types.NetworkInterface) []string {
+	ids := make([]string, 0, len(enis))
+	for _, eni := range enis {
+		if eni.NetworkInterfaceId != nil {
+			ids = append(ids, *eni.NetworkInterfaceId)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,94 @@
+package eip
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// APICallError is a failed AWS API call. Binder errors wrap it, so callers
+// can read the fields support needs with errors.AsType:
+//
+//	if apiErr, ok := errors.AsType[*APICallError](err); ok {
+//		log.Printf("request ID %s", apiErr.RequestID)
+//	}
+//
+// Its message is the SDK error's, so wrapping does not change how errors
+// read.
+type APICallError struct {
+	// Operation is the API operation, e.g. "AssociateAddress".
+	Operation string `json:"operation"`
+	// Code is the AWS error code, e.g. "InvalidAllocationID.NotFound". It is
+	// empty when no response was received.
+	Code string `json:"code,omitempty"`
+	// HTTPStatus is the response status code, or zero without a response.
+	HTTPStatus int    `json:"http_status,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	// Resources are the IDs and addresses the call was about.
+	Resources []string `json:"resources,omitempty"`
+	Err       error    `json:"-"`
+}
+
+func (e *APICallError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APICallError) Unwrap() error {
+	return e.Err
+}
+
+// Details formats the fields other than Err as space-separated key=value
+// pairs, omitting empty ones.
+func (e *APICallError) Details() string {
+	fields := []string{"operation=" + e.Operation}
+	if e.Code != "" {
+		fields = append(fields, "code="+e.Code)
+	}
+	if e.HTTPStatus != 0 {
+		fields = append(fields, fmt.Sprintf("http_status=%d", e.HTTPStatus))
+	}
+	if e.RequestID != "" {
+		fields = append(fields, "request_id="+e.RequestID)
+	}
+	if len(e.Resources) > 0 {
+		fields = append(fields, "resources="+strings.Join(e.Resources, ","))
+	}
+	return strings.Join(fields, " ")
+}
+
+// newAPICallError wraps err from calling operation on resources. Empty
+// resources are dropped.
+func newAPICallError(operation string, err error, resources ...string) error {
+	apiErr := &APICallError{Operation: operation, RequestID: requestID(middleware.Metadata{}, err), Err: err}
+	if opErr, ok := errors.AsType[*smithy.OperationError](err); ok {
+		apiErr.Operation = opErr.Operation()
+	}
+	if codeErr, ok := errors.AsType[smithy.APIError](err); ok {
+		apiErr.Code = codeErr.ErrorCode()
+	}
+	if responseErr, ok := errors.AsType[*smithyhttp.ResponseError](err); ok {
+		apiErr.HTTPStatus = responseErr.HTTPStatusCode()
+	}
+	for _, resource := range resources {
+		if resource != "" {
+			apiErr.Resources = append(apiErr.Resources, resource)
+		}
+	}
+	return apiErr
+}
+
+// requestID returns the AWS request ID of a call from its result metadata
+// or, for a failed call, from its error.
+func requestID(metadata middleware.Metadata, err error) string {
+	if responseErr, ok := errors.AsType[*awshttp.ResponseError](err); ok {
+		return responseErr.ServiceRequestID()
+	}
+	id, _ := awsmiddleware.GetRequestIDMetadata(metadata)
+	return id
+}
@@ -0,0 +1,117 @@
+package eip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// sdkError builds an error shaped like the ones the SDK returns for a
+// failed EC2 call.
+func sdkError(operation, code string, status int, requestID string) error {
+	return &smithy.OperationError{
+		ServiceID:     "EC2",
+		OperationName: operation,
+		Err: &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+				Err:      &smithy.GenericAPIError{Code: code, Message: "denied"},
+			},
+			RequestID: requestID,
+		},
+	}
+}
+
+func TestBindErrorCarriesAPICallFields(t *testing.T) {
+	const targetIP = "54.162.153.80"
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeAddresses = func(*ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		return &ec2.DescribeAddressesOutput{
+			Addresses: []types.Address{elasticAddress(targetIP, "eipalloc-111", "")},
+		}, nil
+	}
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+	}
+	ec2Fake.associateAddress = func(*ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+		return nil, sdkError("AssociateAddress", "UnauthorizedOperation", http.StatusForbidden, "req-123")
+	}
+
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata("i-api")), silentLogger())
+	_, err := binder.Bind(context.Background(), targetIP)
+	apiErr, ok := errors.AsType[*APICallError](err)
+	if !ok {
+		t.Fatalf("error = %v, want APICallError", err)
+	}
+	if apiErr.Operation != "AssociateAddress" || apiErr.Code != "UnauthorizedOperation" ||
+		apiErr.HTTPStatus != http.StatusForbidden || apiErr.RequestID != "req-123" {
+		t.Fatalf("APICallError = %+v", apiErr)
+	}
+	requireStrings(t, apiErr.Resources, []string{"eipalloc-111", "eni-primary", "i-api"}, "Resources")
+	if got, want := apiErr.Details(), "operation=AssociateAddress code=UnauthorizedOperation http_status=403 request_id=req-123 resources=eipalloc-111,eni-primary,i-api"; got != want {
+		t.Fatalf("Details() = %q, want %q", got, want)
+	}
+}
+
+func TestNewAPICallErrorWithoutResponse(t *testing.T) {
+	err := newAPICallError("DescribeAddresses", context.DeadlineExceeded, "54.162.153.80", "")
+	apiErr, ok := errors.AsType[*APICallError](err)
+	if !ok {
+		t.Fatalf("error = %v, want APICallError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || err.Error() != context.DeadlineExceeded.Error() {
+		t.Fatalf("error = %v, want wrapped deadline error with its message", err)
+	}
+	if got, want := apiErr.Details(), "operation=DescribeAddresses resources=54.162.153.80"; got != want {
+		t.Fatalf("Details() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorDetailsJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "API call",
+			err:  newAPICallError("DisassociateAddress", sdkError("DisassociateAddress", "InvalidAssociationID.NotFound", 400, "req-9"), "eipassoc-1"),
+			want: `{"message":"operation error EC2: DisassociateAddress, https response error StatusCode: 400, RequestID: req-9, api error InvalidAssociationID.NotFound: denied","operation":"DisassociateAddress","code":"InvalidAssociationID.NotFound","http_status":400,"request_id":"req-9","resources":["eipassoc-1"]}`,
+		},
+		{
+			name: "wrapped API call",
+			err:  fmt.Errorf("describe tags of instance i-1: %w", newAPICallError("DescribeTags", errors.New("throttled"), "i-1")),
+			want: `{"message":"describe tags of instance i-1: throttled","operation":"DescribeTags","resources":["i-1"]}`,
+		},
+		{
+			name: "other error",
+			err:  errors.New("no addresses found for 54.162.153.80"),
+			want: `{"message":"no addresses found for 54.162.153.80"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(NewErrorDetails(tt.err))
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("JSON = %s\nwant   %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package eip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Operations reported in AuditRecord.Operation.
+const (
+	AuditOperationBind   = "bind"
+	AuditOperationUnbind = "unbind"
+)
+
+// AuditSink stores AuditRecords. Binder writes one record per Bind and
+// Unbind, whether it succeeded or not.
+type AuditSink interface {
+	WriteAudit(record *AuditRecord) error
+}
+
+// AuditRecord is everything one Bind or Unbind did.
+type AuditRecord struct {
+	Time     time.Time `json:"time"`
+	Finished time.Time `json:"finished"`
+	// Operation is AuditOperationBind or AuditOperationUnbind.
+	Operation string `json:"operation"`
+	// Target is the requested target, or the released address for an unbind.
+	Target string `json:"target"`
+	// Messages are the log lines the operation printed, in order.
+	Messages []AuditMessage `json:"messages"`
+	// Calls are the EC2 and Route 53 API calls the operation issued, in
+	// order.
+	Calls []AuditCall `json:"calls"`
+	// Result is the new binding after a bind, or the released binding after
+	// an unbind. Its Previous* fields name the observed previous holder.
+	Result *BindResult `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// AuditMessage is one log line of an AuditRecord.
+type AuditMessage struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// AuditCall is one AWS API call of an AuditRecord.
+type AuditCall struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	RequestID string    `json:"request_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// audited runs op on a copy of b whose log lines and EC2 and Route 53 calls
+// are collected into a record written to b.Audit. Without Audit, op runs on
+// b.
+func (b *Binder) audited(ctx context.Context, operation, target string, op func(*Binder) (*BindResult, error)) (*BindResult, error) {
+	if b.Audit == nil {
+		return op(b)
+	}
+	record := &AuditRecord{Time: time.Now().UTC(), Operation: operation, Target: target}
+	trail := &auditTrail{record: record, logger: b.Logger}
+	audited := *b
+	audited.Audit = nil
+	audited.Logger = log.New(trail, "", 0)
+	audited.EC2 = &auditEC2{EC2API: b.EC2, trail: trail}
+	if b.AddressEC2 != nil {
+		audited.AddressEC2 = &auditEC2{EC2API: b.AddressEC2, trail: trail}
+	}
+	if b.DNS != nil {
+		dns := *b.DNS
+		dns.Provider = auditDNSProvider(b.DNS.Provider, trail)
+		audited.DNS = &dns
+	}
+
+	result, err := op(&audited)
+	record.Finished = time.Now().UTC()
+	record.Result = result
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if werr := b.Audit.WriteAudit(record); werr != nil {
+		b.Logger.Printf("audit: %v", werr)
+	}
+	return result, err
+}
+
+// auditTrail collects the log lines and API calls of one AuditRecord. As
+// an io.Writer it receives the audited Binder's log output and forwards
+// each line to the original logger.
+type auditTrail struct {
+	mu     sync.Mutex
+	record *AuditRecord
+	logger *log.Logger
+}
+
+func (t *auditTrail) Write(p []byte) (int, error) {
+	message := strings.TrimSuffix(string(p), "\n")
+	t.logger.Print(message)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record.Messages = append(t.record.Messages, AuditMessage{Time: time.Now().UTC(), Message: message})
+	return len(p), nil
+}
+
+func (t *auditTrail) call(operation string, metadata middleware.Metadata, err error) {
+	call := AuditCall{Time: time.Now().UTC(), Operation: operation, RequestID: requestID(metadata, err)}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record.Calls = append(t.record.Calls, call)
+}
+
+// auditEC2 records each call to EC2API in trail.
+type auditEC2 struct {
+	EC2API
+	trail *auditTrail
+}
+
+// auditCall calls operation through fn and records it in trail, with the
+// request ID from the output's ResultMetadata or from the error.
+func auditCall[In, Out, Opt any](ctx context.Context, trail *auditTrail, operation string, fn func(context.Context, In, ...Opt) (*Out, error), params In, optFns []Opt) (*Out, error) {
+	out, err := fn(ctx, params, optFns...)
+	trail.call(operation, resultMetadata(out), err)
+	return out, err
+}
+
+// resultMetadata returns the ResultMetadata field every SDK output struct
+// has, or empty metadata when out is nil.
+func resultMetadata[Out any](out *Out) middleware.Metadata {
+	if out == nil {
+		return middleware.Metadata{}
+	}
+	field := reflect.ValueOf(out).Elem().FieldByName("ResultMetadata")
+	if !field.IsValid() {
+		return middleware.Metadata{}
+	}
+	metadata, _ := field.Interface().(middleware.Metadata)
+	return metadata
+}
+
+func (a *auditEC2) DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	return auditCall(ctx, a.trail, "DescribeAddresses", a.EC2API.DescribeAddresses, params, optFns)
+}
+
+func (a *auditEC2) DescribeNetworkInterfaces(ctx context.Context, params *ec2.DescribeNetworkInterfacesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	return auditCall(ctx, a.trail, "DescribeNetworkInterfaces", a.EC2API.DescribeNetworkInterfaces, params, optFns)
+}
+
+func (a *auditEC2) AssociateAddress(ctx context.Context, params *ec2.AssociateAddressInput, optFns ...func(*ec2.Options)) (*ec2.AssociateAddressOutput, error) {
+	return auditCall(ctx, a.trail, "AssociateAddress", a.EC2API.AssociateAddress, params, optFns)
+}
+
+func (a *auditEC2) DisassociateAddress(ctx context.Context, params *ec2.DisassociateAddressInput, optFns ...func(*ec2.Options)) (*ec2.DisassociateAddressOutput, error) {
+	return auditCall(ctx, a.trail, "DisassociateAddress", a.EC2API.DisassociateAddress, params, optFns)
+}
+
+func (a *auditEC2) AssignIpv6Addresses(ctx context.Context, params *ec2.AssignIpv6AddressesInput, optFns ...func(*ec2.Options)) (*ec2.AssignIpv6AddressesOutput, error) {
+	return auditCall(ctx, a.trail, "AssignIpv6Addresses", a.EC2API.AssignIpv6Addresses, params, optFns)
+}
+
+func (a *auditEC2) UnassignIpv6Addresses(ctx context.Context, params *ec2.UnassignIpv6AddressesInput, optFns ...func(*ec2.Options)) (*ec2.UnassignIpv6AddressesOutput, error) {
+	return auditCall(ctx, a.trail, "UnassignIpv6Addresses", a.EC2API.UnassignIpv6Addresses, params, optFns)
+}
+
+func (a *auditEC2) ModifyNetworkInterfaceAttribute(ctx context.Context, params *ec2.ModifyNetworkInterfaceAttributeInput, optFns ...func(*ec2.Options)) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+	return auditCall(ctx, a.trail, "ModifyNetworkInterfaceAttribute", a.EC2API.ModifyNetworkInterfaceAttribute, params, optFns)
+}
+
+func (a *auditEC2) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return auditCall(ctx, a.trail, "DescribeInstances", a.EC2API.DescribeInstances, params, optFns)
+}
+
+func (a *auditEC2) DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	return auditCall(ctx, a.trail, "DescribeSubnets", a.EC2API.DescribeSubnets, params, optFns)
+}
+
+func (a *auditEC2) DescribeTags(ctx context.Context, params *ec2.DescribeTagsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTagsOutput, error) {
+	return auditCall(ctx, a.trail, "DescribeTags", a.EC2API.DescribeTags, params, optFns)
+}
+
+// auditDNSProvider returns provider with its Route 53 calls recorded in
+// trail. Other providers are returned unchanged.
+func auditDNSProvider(provider DNSProvider, trail *auditTrail) DNSProvider {
+	route53Provider, ok := provider.(*Route53Provider)
+	if !ok {
+		return provider
+	}
+	audited := *route53Provider
+	audited.Client = &auditRoute53{Route53API: route53Provider.Client, trail: trail}
+	return &audited
+}
+
+// auditRoute53 records each call to Route53API in trail.
+type auditRoute53 struct {
+	Route53API
+	trail *auditTrail
+}
+
+func (a *auditRoute53) ChangeResourceRecordSets(ctx context.Context, params *route53.ChangeResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return auditCall(ctx, a.trail, "ChangeResourceRecordSets", a.Route53API.ChangeResourceRecordSets, params, optFns)
+}
+
+func (a *auditRoute53) ListResourceRecordSets(ctx context.Context, params *route53.ListResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error) {
+	return auditCall(ctx, a.trail, "ListResourceRecordSets", a.Route53API.ListResourceRecordSets, params, optFns)
+}
+
+// AuditOptions configures the audit log file.
+type AuditOptions struct {
+	Path       string
+	MaxBytes   int64
+	MaxBackups int
+}
+
+// DefaultAuditMaxBytes and DefaultAuditMaxBackups are the rotation defaults
+// of FileAuditSink.
+const (
+	DefaultAuditMaxBytes   = 10 << 20
+	DefaultAuditMaxBackups = 5
+)
+
+// FileAuditSink appends AuditRecords as JSON lines to a file. When a record
+// would grow the file past MaxBytes, the file is rotated: PATH becomes
+// PATH.1, PATH.1 becomes PATH.2, and so on, keeping MaxBackups old files.
+type FileAuditSink struct {
+	Path string
+	// MaxBytes is the size at which the file is rotated. Zero disables
+	// rotation.
+	MaxBytes int64
+	// MaxBackups is the number of rotated files kept. Values below one keep
+	// one, so rotation never deletes the live file's records outright.
+	MaxBackups int
+
+	mu sync.Mutex
+	// file is nil after Close, or when reopening it after a rotation failed;
+	// the next write then tries to open it again.
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+// OpenFileAuditSink opens path for appending, creating it if needed.
+func OpenFileAuditSink(path string, maxBytes int64, maxBackups int) (*FileAuditSink, error) {
+	s := &FileAuditSink{Path: path, MaxBytes: maxBytes, MaxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileAuditSink) open() error {
+	file, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// WriteAudit appends record as one JSON line. When rotation fails, the
+// record is still appended to the current file and the rotation error is
+// returned. When the file could not be reopened after a rotation, each
+// write tries to open it again.
+func (s *FileAuditSink) WriteAudit(record *AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("write audit log: %s is closed", s.Path)
+	}
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	var rotateErr error
+	if s.MaxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.MaxBytes {
+		rotateErr = s.rotate()
+		if s.file == nil {
+			return rotateErr
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return rotateErr
+}
+
+// rotate shifts the backups and starts a new file. The file at Path is
+// reopened even when a rename fails, so later records are not lost; the
+// next write past MaxBytes retries the rotation.
+func (s *FileAuditSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err == nil {
+		err = s.shiftBackups()
+	}
+	if openErr := s.open(); openErr != nil {
+		err = errors.Join(err, openErr)
+	}
+	if err != nil {
+		return fmt.Errorf("rotate audit log: %w", err)
+	}
+	return nil
+}
+
+// shiftBackups renames PATH.N to PATH.N+1, dropping the oldest, and PATH to
+// PATH.1.
+func (s *FileAuditSink) shiftBackups() error {
+	for i := max(s.MaxBackups, 1) - 1; i >= 1; i-- {
+		older := fmt.Sprintf("%s.%d", s.Path, i)
+		if err := os.Rename(older, fmt.Sprintf("%s.%d", s.Path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(s.Path, s.Path+".1")
+}
+
+// Close closes the file.
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,284 @@
+package eip
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+type fakeAuditSink struct {
+	records []*AuditRecord
+}
+
+func (f *fakeAuditSink) WriteAudit(record *AuditRecord) error {
+	f.records = append(f.records, record)
+	return nil
+}
+
+func TestBindWritesAuditRecord(t *testing.T) {
+	const (
+		targetIP   = "54.162.153.80"
+		instanceID = "i-audit"
+	)
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeAddresses = func(*ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		address := elasticAddress(targetIP, "eipalloc-111", "eipassoc-old")
+		address.NetworkInterfaceId = new("eni-old")
+		address.InstanceId = new("i-old")
+		out := &ec2.DescribeAddressesOutput{Addresses: []types.Address{address}}
+		awsmiddleware.SetRequestIDMetadata(&out.ResultMetadata, "req-describe")
+		return out, nil
+	}
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+	}
+	ec2Fake.associateAddress = func(*ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+		return nil, errors.New("throttled")
+	}
+
+	sink := &fakeAuditSink{}
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID)), silentLogger())
+	binder.Audit = sink
+	if _, err := binder.Bind(context.Background(), targetIP); err == nil {
+		t.Fatal("expected error")
+	}
+	ec2Fake.assertCalls([]string{"DescribeAddresses", "DescribeNetworkInterfaces", "AssociateAddress"})
+
+	if len(sink.records) != 1 {
+		t.Fatalf("records = %d, want 1", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.Operation != AuditOperationBind || record.Target != targetIP || record.Result != nil {
+		t.Fatalf("record = %+v, want failed bind of %s", record, targetIP)
+	}
+	if record.Time.IsZero() || record.Finished.Before(record.Time) {
+		t.Fatalf("record times = %v to %v", record.Time, record.Finished)
+	}
+	if record.Error == "" {
+		t.Fatal("record has no error")
+	}
+	var calls []string
+	for _, call := range record.Calls {
+		calls = append(calls, call.Operation+" "+call.RequestID+" "+call.Error)
+	}
+	requireStrings(t, calls, []string{
+		"DescribeAddresses req-describe ",
+		"DescribeNetworkInterfaces  ",
+		"AssociateAddress  throttled",
+	}, "calls")
+	var messages []string
+	for _, message := range record.Messages {
+		messages = append(messages, message.Message)
+	}
+	requireStrings(t, messages, []string{
+		"Associating EIP 54.162.153.80 (allocation=eipalloc-111) to ENI eni-primary on instance i-audit",
+	}, "messages")
+}
+
+func TestUnbindWritesAuditRecord(t *testing.T) {
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{describeENIByID(t, primaryENI("2001:db8::1"))}
+	ec2Fake.unassignIPv6Addresses = func(*ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error) {
+		return &ec2.UnassignIpv6AddressesOutput{}, nil
+	}
+
+	sink := &fakeAuditSink{}
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, nil), silentLogger())
+	binder.Audit = sink
+	result := &BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8::1", NetworkInterfaceID: "eni-primary", InstanceID: "i-audit"}
+	if _, err := binder.Unbind(context.Background(), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sink.records) != 1 {
+		t.Fatalf("records = %d, want 1", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.Operation != AuditOperationUnbind || record.Target != "2001:db8::1" || record.Result != result || record.Error != "" {
+		t.Fatalf("record = %+v, want unbind of 2001:db8::1", record)
+	}
+	var calls []string
+	for _, call := range record.Calls {
+		calls = append(calls, call.Operation)
+	}
+	requireStrings(t, calls, []string{"DescribeNetworkInterfaces", "UnassignIpv6Addresses"}, "calls")
+}
+
+func TestUnbindAuditsRoute53Calls(t *testing.T) {
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{describeENIByID(t, primaryENI("2001:db8::1"))}
+	ec2Fake.unassignIPv6Addresses = func(*ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error) {
+		return &ec2.UnassignIpv6AddressesOutput{}, nil
+	}
+
+	sink := &fakeAuditSink{}
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, nil), silentLogger())
+	binder.Audit = sink
+	binder.DNS = &DNSUpdater{
+		Provider: NewRoute53Provider(&fakeRoute53{
+			requestID: "req-route53",
+			listings:  [][]route53types.ResourceRecordSet{recordSet("api.example.com.", route53types.RRTypeAaaa, 60, "2001:db8::1")},
+		}, "Z0123456789ABC"),
+		Name: "api.example.com",
+	}
+	result := &BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8::1", NetworkInterfaceID: "eni-primary", InstanceID: "i-audit"}
+	if _, err := binder.Unbind(context.Background(), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sink.records) != 1 {
+		t.Fatalf("records = %d, want 1", len(sink.records))
+	}
+	var calls []string
+	for _, call := range sink.records[0].Calls {
+		calls = append(calls, call.Operation+" "+call.RequestID)
+	}
+	requireStrings(t, calls, []string{
+		"DescribeNetworkInterfaces ",
+		"UnassignIpv6Addresses ",
+		"ListResourceRecordSets req-route53",
+		"ChangeResourceRecordSets req-route53",
+	}, "calls")
+}
+
+func TestFileAuditSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := OpenFileAuditSink(path, 200, 2)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer sink.Close()
+
+	for _, target := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4"} {
+		if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: target}); err != nil {
+			t.Fatalf("write %s: %v", target, err)
+		}
+	}
+
+	// Each record is over 100 bytes, so every file holds one record and the
+	// oldest falls off after two backups.
+	for suffix, want := range map[string]string{"": "192.0.2.4", ".1": "192.0.2.3", ".2": "192.0.2.2"} {
+		requireAuditTargets(t, path+suffix, want)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("stat %s.3: %v, want not exist", path, err)
+	}
+}
+
+func TestFileAuditSinkKeepsWritingAfterFailedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := OpenFileAuditSink(path, 100, 1)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer sink.Close()
+	if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: "192.0.2.1"}); err != nil {
+		t.Fatalf("write 192.0.2.1: %v", err)
+	}
+
+	// A non-empty directory at PATH.1 makes the rename fail.
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: "192.0.2.2"}); err == nil {
+		t.Fatal("expected rotation error, got nil")
+	}
+	requireAuditTargets(t, path, "192.0.2.1", "192.0.2.2")
+
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: "192.0.2.3"}); err != nil {
+		t.Fatalf("write 192.0.2.3: %v", err)
+	}
+	requireAuditTargets(t, path, "192.0.2.3")
+	requireAuditTargets(t, path+".1", "192.0.2.1", "192.0.2.2")
+}
+
+func TestFileAuditSinkReopensAfterFailedReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := OpenFileAuditSink(path, 100, 1)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer sink.Close()
+	if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: "192.0.2.1"}); err != nil {
+		t.Fatalf("write 192.0.2.1: %v", err)
+	}
+
+	// Non-empty directories at PATH.1 and PATH make both the rename and the
+	// reopen fail.
+	for _, dir := range []string{path + ".1", path} {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, "blocker"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: "192.0.2.2"}); err == nil {
+		t.Fatal("expected rotation error, got nil")
+	}
+	err = sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: "192.0.2.3"})
+	if err == nil || !strings.Contains(err.Error(), "open audit log") {
+		t.Fatalf("error = %v, want the open error", err)
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: "192.0.2.4"}); err != nil {
+		t.Fatalf("write 192.0.2.4: %v", err)
+	}
+	requireAuditTargets(t, path, "192.0.2.4")
+}
+
+func TestFileAuditSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, target := range []string{"192.0.2.1", "192.0.2.2"} {
+		sink, err := OpenFileAuditSink(path, DefaultAuditMaxBytes, DefaultAuditMaxBackups)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		if err := sink.WriteAudit(&AuditRecord{Operation: AuditOperationBind, Target: target}); err != nil {
+			t.Fatalf("write %s: %v", target, err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	}
+	requireAuditTargets(t, path, "192.0.2.1", "192.0.2.2")
+}
+
+func requireAuditTargets(t *testing.T, path string, want ...string) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer file.Close()
+	var got []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("decode %s line %q: %v", path, scanner.Text(), err)
+		}
+		got = append(got, record.Target)
+	}
+	requireStrings(t, got, want, path)
+}
@@ -3,11 +3,11 @@ package eip
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"net/netip"
+	"strings"
 
-	ec2imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
@@ -17,11 +17,36 @@ const (
 	IPFamilyIPv6 = "ipv6"
 )
 
-// Binder performs EIP association with the current EC2 instance.
+// AutoIPv6 is a Bind target that asks EC2 to assign a new IPv6 address from
+// the primary ENI subnet instead of moving a specific one.
+const AutoIPv6 = "AUTO_IPV6"
+
+// ipv6PrefixBits is the only IPv6 prefix length EC2 delegates to ENIs.
+const ipv6PrefixBits = 80
+
+// Binder performs EIP association with the current EC2 instance, or with
+// InstanceID when it is set.
 type Binder struct {
 	EC2    EC2API
 	IMDS   MetadataClient
 	Logger *log.Logger
+	// InstanceID, when set, binds to that instance instead of the one
+	// reported by instance metadata, so the binder can run off-instance.
+	InstanceID string
+	// AddressEC2, when set, is used instead of EC2 for Elastic IP calls
+	// (DescribeAddresses, AssociateAddress, DisassociateAddress), so addresses
+	// can be managed with other credentials than the instance's ENIs.
+	AddressEC2 EC2API
+	// PrimaryIPv6 controls whether bound IPv6 addresses must be, or are made,
+	// the ENI's primary IPv6 address.
+	PrimaryIPv6 PrimaryIPv6Mode
+	// Hooks, when set, run commands before and after each Bind.
+	Hooks *Hooks
+	// DNS, when set, publishes the bound address after each Bind and
+	// withdraws it after each Unbind.
+	DNS *DNSUpdater
+	// Audit, when set, receives an AuditRecord for each Bind and Unbind.
+	Audit AuditSink
 }
 
 // NewBinder creates a Binder with the given dependencies.
@@ -39,23 +64,88 @@ func NewBinder(ec2Client EC2API, imds MetadataClient, logger *log.Logger) *Binde
 // BindResult describes the outcome of a Bind operation.
 type BindResult struct {
 	// AlreadyAssociated is true when the target IP was already on this instance.
-	AlreadyAssociated bool
+	AlreadyAssociated bool `json:"already_associated"`
 	// AssociationID is the new IPv4 EIP association ID (empty for IPv6 or when AlreadyAssociated).
-	AssociationID string
-	// InstanceID is the current instance's ID.
-	InstanceID string
+	AssociationID string `json:"association_id,omitempty"`
+	// InstanceID is the ID of the instance the target was bound to.
+	InstanceID string `json:"instance_id"`
 	// Family is the address family: "ipv4" or "ipv6".
-	Family string
+	Family string `json:"family"`
 	// TargetIP is the normalized target IP address.
-	TargetIP string
+	TargetIP string `json:"target_ip"`
 	// NetworkInterfaceID is the ENI that holds the target IP after binding.
-	NetworkInterfaceID string
+	NetworkInterfaceID string `json:"network_interface_id"`
+	// Prefix is true when TargetIP is a delegated IPv6 prefix rather than a single address.
+	Prefix bool `json:"prefix"`
+	// PrimaryIPv6 is true when the bound IPv6 address is the ENI's primary IPv6
+	// address. Newly assigned addresses are only checked when Binder.PrimaryIPv6
+	// is set; otherwise they are reported as not primary.
+	PrimaryIPv6 bool `json:"primary_ipv6"`
+	// PreviousNetworkInterfaceID is the ENI the target was moved away from,
+	// empty when it was unassigned or AlreadyAssociated.
+	PreviousNetworkInterfaceID string `json:"previous_network_interface_id,omitempty"`
+	// PreviousInstanceID is the instance PreviousNetworkInterfaceID was
+	// attached to, if any.
+	PreviousInstanceID string `json:"previous_instance_id,omitempty"`
 }
 
-// Bind associates the given IPv4 Elastic IP or IPv6 address with the current EC2 instance.
+// Bind associates the given IPv4 Elastic IP, IPv6 address, or delegated IPv6
+// prefix with the current EC2 instance.
+//
+// IPv4 uses Elastic IP allocation APIs. IPv6 addresses and prefixes use ENI
+// IPv6 assignment APIs. Passing AutoIPv6 assigns a new IPv6 address picked by
+// EC2 and reports it in BindResult.TargetIP.
+//
+// When Hooks is set, its pre-bind hooks run first and its post-bind hooks
+// run after a successful bind. When DNS is set, the record is published
+// before the post-bind hooks run; a failure to publish fails the Bind even
+// though the address has already moved, so callers retry.
 //
-// IPv4 uses Elastic IP allocation APIs. IPv6 uses ENI IPv6 assignment APIs.
+// When Audit is set, the log lines, EC2 calls, and outcome are recorded.
 func (b *Binder) Bind(ctx context.Context, targetIP string) (*BindResult, error) {
+	return b.audited(ctx, AuditOperationBind, targetIP, func(b *Binder) (*BindResult, error) {
+		return b.bindWithHooks(ctx, targetIP)
+	})
+}
+
+func (b *Binder) bindWithHooks(ctx context.Context, targetIP string) (*BindResult, error) {
+	// A prefix has no A or AAAA record; refuse before moving it rather than
+	// failing the publish afterwards.
+	if b.DNS != nil && strings.Contains(targetIP, "/") {
+		return nil, fmt.Errorf("cannot publish IPv6 prefix %s in DNS record %s", targetIP, b.DNS.Name)
+	}
+	if b.Hooks != nil {
+		if err := b.Hooks.runPreBind(ctx, b.Logger, targetIP); err != nil {
+			return nil, err
+		}
+	}
+	result, err := b.bind(ctx, targetIP)
+	if err != nil {
+		return nil, err
+	}
+	if b.DNS != nil {
+		if err := b.DNS.Publish(ctx, b.Logger, result); err != nil {
+			return nil, err
+		}
+	}
+	if b.Hooks != nil {
+		b.Hooks.runPostBind(ctx, b.Logger, targetIP, result)
+	}
+	return result, nil
+}
+
+func (b *Binder) bind(ctx context.Context, targetIP string) (*BindResult, error) {
+	if targetIP == AutoIPv6 {
+		return b.bindIPv6(ctx, netip.Addr{})
+	}
+	if strings.Contains(targetIP, "/") {
+		targetPrefix, err := parseTargetPrefix(targetIP)
+		if err != nil {
+			return nil, err
+		}
+		return b.bindIPv6Prefix(ctx, targetPrefix)
+	}
+
 	targetAddr, err := parseTargetAddr(targetIP)
 	if err != nil {
 		return nil, err
@@ -75,33 +165,60 @@ func parseTargetAddr(targetIP string) (netip.Addr, error) {
 	return addr.Unmap(), nil
 }
 
-func (b *Binder) getInstanceID(ctx context.Context) (string, error) {
-	out, err := b.IMDS.GetMetadata(ctx, &ec2imds.GetMetadataInput{Path: "instance-id"})
+func parseTargetPrefix(target string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(target)
 	if err != nil {
-		return "", fmt.Errorf("get instance-id: %w", err)
+		return netip.Prefix{}, fmt.Errorf("invalid IP prefix: %s", target)
+	}
+	if !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		return netip.Prefix{}, fmt.Errorf("only IPv6 prefixes are supported: %s", target)
 	}
-	if out == nil || out.Content == nil {
-		return "", fmt.Errorf("get instance-id: empty metadata response")
+	if prefix.Bits() != ipv6PrefixBits {
+		return netip.Prefix{}, fmt.Errorf("IPv6 prefix %s must be a /%d", target, ipv6PrefixBits)
 	}
-	defer out.Content.Close() //nolint:errcheck
+	if prefix != prefix.Masked() {
+		return netip.Prefix{}, fmt.Errorf("IPv6 prefix %s has host bits set (expected %s)", target, prefix.Masked())
+	}
+	return prefix, nil
+}
 
-	instanceID, err := io.ReadAll(out.Content)
-	if err != nil {
-		return "", fmt.Errorf("get instance-id: %w", err)
+// addressEC2 returns the client for Elastic IP calls.
+func (b *Binder) addressEC2() EC2API {
+	if b.AddressEC2 != nil {
+		return b.AddressEC2
 	}
-	return string(instanceID), nil
+	return b.EC2
+}
+
+func (b *Binder) getInstanceID(ctx context.Context) (string, error) {
+	if b.InstanceID != "" {
+		return b.InstanceID, nil
+	}
+	return CurrentInstanceID(ctx, b.IMDS)
 }
 
 func (b *Binder) bindIPv4(ctx context.Context, targetIP string) (*BindResult, error) {
 	// 1. Describe the EIP allocation.
-	descOut, err := b.EC2.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+	descOut, err := b.addressEC2().DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
 		PublicIps: []string{targetIP},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("describe addresses for %s: %w", targetIP, err)
+		return nil, fmt.Errorf("describe addresses for %s: %w", targetIP, newAPICallError("DescribeAddresses", err, targetIP))
 	}
-	if len(descOut.Addresses) == 0 {
+	// DescribeAddresses is not paginated; a public IP filter should match at
+	// most one allocation.
+	switch len(descOut.Addresses) {
+	case 0:
 		return nil, fmt.Errorf("no addresses found for %s", targetIP)
+	case 1:
+	default:
+		var allocationIDs []string
+		for _, address := range descOut.Addresses {
+			if address.AllocationId != nil {
+				allocationIDs = append(allocationIDs, *address.AllocationId)
+			}
+		}
+		return nil, newAmbiguousMatchError("Elastic IP allocations for "+targetIP, allocationIDs)
 	}
 	address := descOut.Addresses[0]
 
@@ -137,13 +254,14 @@ func (b *Binder) bindIPv4(ctx context.Context, targetIP string) (*BindResult, er
 	b.Logger.Printf("Associating EIP %s (allocation=%s) to ENI %s on instance %s",
 		targetIP, *address.AllocationId, *networkInterfaceID, instanceID)
 
-	assocOut, err := b.EC2.AssociateAddress(ctx, &ec2.AssociateAddressInput{
+	assocOut, err := b.addressEC2().AssociateAddress(ctx, &ec2.AssociateAddressInput{
 		AllocationId:       address.AllocationId,
 		AllowReassociation: new(true),
 		NetworkInterfaceId: networkInterfaceID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("associate EIP %s with instance %s: %w", targetIP, instanceID, err)
+		return nil, fmt.Errorf("associate EIP %s with instance %s: %w", targetIP, instanceID,
+			newAPICallError("AssociateAddress", err, *address.AllocationId, *networkInterfaceID, instanceID))
 	}
 
 	assocID := ""
@@ -153,15 +271,19 @@ func (b *Binder) bindIPv4(ctx context.Context, targetIP string) (*BindResult, er
 
 	b.Logger.Printf("Successfully associated EIP %s with instance %s (association=%s)", targetIP, instanceID, assocID)
 	return &BindResult{
-		AlreadyAssociated:  false,
-		AssociationID:      assocID,
-		InstanceID:         instanceID,
-		Family:             IPFamilyIPv4,
-		TargetIP:           targetIP,
-		NetworkInterfaceID: *networkInterfaceID,
+		AlreadyAssociated:          false,
+		AssociationID:              assocID,
+		InstanceID:                 instanceID,
+		Family:                     IPFamilyIPv4,
+		TargetIP:                   targetIP,
+		NetworkInterfaceID:         *networkInterfaceID,
+		PreviousNetworkInterfaceID: aws.ToString(address.NetworkInterfaceId),
+		PreviousInstanceID:         aws.ToString(address.InstanceId),
 	}, nil
 }
 
+// bindIPv6 moves targetAddr to the primary ENI. An invalid (zero) targetAddr
+// asks EC2 to assign a new address from the primary ENI subnet instead.
 func (b *Binder) bindIPv6(ctx context.Context, targetAddr netip.Addr) (*BindResult, error) {
 	targetIP := targetAddr.String()
 
@@ -179,19 +301,29 @@ func (b *Binder) bindIPv6(ctx context.Context, targetAddr netip.Addr) (*BindResu
 	if networkInterfaceID == nil {
 		return nil, fmt.Errorf("primary network interface for instance %s has no ID", instanceID)
 	}
+	if !targetAddr.IsValid() {
+		if err := b.precheckPrimaryIPv6(primaryENI, ""); err != nil {
+			return nil, err
+		}
+		return b.assignIPv6(ctx, instanceID, *networkInterfaceID, "")
+	}
 	if primaryENI.SubnetId == nil {
 		return nil, fmt.Errorf("primary network interface %s has no subnet ID", *networkInterfaceID)
 	}
 
 	if hasIPv6(primaryENI, targetIP) {
 		b.Logger.Printf("IPv6 %s is already assigned to ENI %s on instance %s", targetIP, *networkInterfaceID, instanceID)
-		return &BindResult{
+		result := &BindResult{
 			AlreadyAssociated:  true,
 			InstanceID:         instanceID,
 			Family:             IPFamilyIPv6,
 			TargetIP:           targetIP,
 			NetworkInterfaceID: *networkInterfaceID,
-		}, nil
+		}
+		if err := b.checkPrimaryIPv6(ctx, result, primaryENI); err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
 
 	if err := b.ensureIPv6InSubnet(ctx, targetAddr, *primaryENI.SubnetId, *networkInterfaceID); err != nil {
@@ -207,13 +339,20 @@ func (b *Binder) bindIPv6(ctx context.Context, targetAddr netip.Addr) (*BindResu
 	}
 	if currentENI != nil && *currentENI.NetworkInterfaceId == *networkInterfaceID {
 		b.Logger.Printf("IPv6 %s is already assigned to ENI %s on instance %s", targetIP, *networkInterfaceID, instanceID)
-		return &BindResult{
+		result := &BindResult{
 			AlreadyAssociated:  true,
 			InstanceID:         instanceID,
 			Family:             IPFamilyIPv6,
 			TargetIP:           targetIP,
 			NetworkInterfaceID: *networkInterfaceID,
-		}, nil
+		}
+		if err := b.checkPrimaryIPv6(ctx, result, currentENI); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if err := b.precheckPrimaryIPv6(primaryENI, targetIP); err != nil {
+		return nil, err
 	}
 	if currentENI != nil {
 		b.Logger.Printf("Unassigning IPv6 %s from ENI %s", targetIP, *currentENI.NetworkInterfaceId)
@@ -222,35 +361,172 @@ func (b *Binder) bindIPv6(ctx context.Context, targetAddr netip.Addr) (*BindResu
 			Ipv6Addresses:      []string{targetIP},
 		})
 		if err != nil {
-			return nil, fmt.Errorf("unassign IPv6 %s from ENI %s: %w", targetIP, *currentENI.NetworkInterfaceId, err)
+			return nil, fmt.Errorf("unassign IPv6 %s from ENI %s: %w", targetIP, *currentENI.NetworkInterfaceId,
+				newAPICallError("UnassignIpv6Addresses", err, *currentENI.NetworkInterfaceId, targetIP))
 		}
 	}
 
-	b.Logger.Printf("Assigning IPv6 %s to ENI %s on instance %s", targetIP, *networkInterfaceID, instanceID)
-	assignOut, err := b.EC2.AssignIpv6Addresses(ctx, &ec2.AssignIpv6AddressesInput{
-		NetworkInterfaceId: networkInterfaceID,
-		Ipv6Addresses:      []string{targetIP},
-	})
+	result, err := b.assignIPv6(ctx, instanceID, *networkInterfaceID, targetIP)
+	if err != nil {
+		return nil, err
+	}
+	if currentENI != nil {
+		result.PreviousNetworkInterfaceID = *currentENI.NetworkInterfaceId
+		result.PreviousInstanceID = attachedInstanceID(currentENI)
+	}
+	return result, nil
+}
+
+// assignIPv6 assigns targetIP to networkInterfaceID. An empty targetIP asks
+// EC2 to pick one address from the ENI subnet; the picked address is reported
+// in BindResult.TargetIP.
+func (b *Binder) assignIPv6(ctx context.Context, instanceID, networkInterfaceID, targetIP string) (*BindResult, error) {
+	in := &ec2.AssignIpv6AddressesInput{
+		NetworkInterfaceId: new(networkInterfaceID),
+	}
+	if targetIP == "" {
+		b.Logger.Printf("Assigning a new IPv6 address to ENI %s on instance %s", networkInterfaceID, instanceID)
+		in.Ipv6AddressCount = new(int32(1))
+	} else {
+		b.Logger.Printf("Assigning IPv6 %s to ENI %s on instance %s", targetIP, networkInterfaceID, instanceID)
+		in.Ipv6Addresses = []string{targetIP}
+	}
+
+	assignOut, err := b.EC2.AssignIpv6Addresses(ctx, in)
 	if err != nil {
-		return nil, fmt.Errorf("assign IPv6 %s to ENI %s: %w", targetIP, *networkInterfaceID, err)
+		err = newAPICallError("AssignIpv6Addresses", err, networkInterfaceID, targetIP)
+		if targetIP == "" {
+			return nil, fmt.Errorf("assign new IPv6 to ENI %s: %w", networkInterfaceID, err)
+		}
+		return nil, fmt.Errorf("assign IPv6 %s to ENI %s: %w", targetIP, networkInterfaceID, err)
 	}
 
 	if len(assignOut.AssignedIpv6Addresses) > 0 {
 		targetIP = assignOut.AssignedIpv6Addresses[0]
 	}
+	if targetIP == "" {
+		return nil, fmt.Errorf("assign new IPv6 to ENI %s: no address returned", networkInterfaceID)
+	}
 
-	b.Logger.Printf("Successfully assigned IPv6 %s to ENI %s on instance %s", targetIP, *networkInterfaceID, instanceID)
-	return &BindResult{
+	b.Logger.Printf("Successfully assigned IPv6 %s to ENI %s on instance %s", targetIP, networkInterfaceID, instanceID)
+	result := &BindResult{
 		AlreadyAssociated:  false,
 		InstanceID:         instanceID,
 		Family:             IPFamilyIPv6,
 		TargetIP:           targetIP,
+		NetworkInterfaceID: networkInterfaceID,
+	}
+	if b.PrimaryIPv6 != PrimaryIPv6Ignore {
+		eni, err := b.describeNetworkInterface(ctx, networkInterfaceID)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.checkPrimaryIPv6(ctx, result, eni); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (b *Binder) bindIPv6Prefix(ctx context.Context, targetPrefix netip.Prefix) (*BindResult, error) {
+	target := targetPrefix.String()
+
+	instanceID, err := b.getInstanceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	primaryENI, err := b.findPrimaryNetworkInterface(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	networkInterfaceID := primaryENI.NetworkInterfaceId
+	if networkInterfaceID == nil {
+		return nil, fmt.Errorf("primary network interface for instance %s has no ID", instanceID)
+	}
+	if primaryENI.SubnetId == nil {
+		return nil, fmt.Errorf("primary network interface %s has no subnet ID", *networkInterfaceID)
+	}
+
+	if hasIPv6Prefix(primaryENI, targetPrefix) {
+		b.Logger.Printf("IPv6 prefix %s is already assigned to ENI %s on instance %s", target, *networkInterfaceID, instanceID)
+		return &BindResult{
+			AlreadyAssociated:  true,
+			InstanceID:         instanceID,
+			Family:             IPFamilyIPv6,
+			TargetIP:           target,
+			NetworkInterfaceID: *networkInterfaceID,
+			Prefix:             true,
+		}, nil
+	}
+
+	if err := b.ensureIPv6PrefixInSubnet(ctx, targetPrefix, *primaryENI.SubnetId, *networkInterfaceID); err != nil {
+		return nil, err
+	}
+
+	currentENI, err := b.findNetworkInterfaceByIPv6Prefix(ctx, targetPrefix, *primaryENI.SubnetId)
+	if err != nil {
+		return nil, err
+	}
+	if currentENI != nil && currentENI.NetworkInterfaceId == nil {
+		return nil, fmt.Errorf("network interface for IPv6 prefix %s has no ID", target)
+	}
+	if currentENI != nil && *currentENI.NetworkInterfaceId == *networkInterfaceID {
+		b.Logger.Printf("IPv6 prefix %s is already assigned to ENI %s on instance %s", target, *networkInterfaceID, instanceID)
+		return &BindResult{
+			AlreadyAssociated:  true,
+			InstanceID:         instanceID,
+			Family:             IPFamilyIPv6,
+			TargetIP:           target,
+			NetworkInterfaceID: *networkInterfaceID,
+			Prefix:             true,
+		}, nil
+	}
+	if currentENI != nil {
+		b.Logger.Printf("Unassigning IPv6 prefix %s from ENI %s", target, *currentENI.NetworkInterfaceId)
+		_, err = b.EC2.UnassignIpv6Addresses(ctx, &ec2.UnassignIpv6AddressesInput{
+			NetworkInterfaceId: currentENI.NetworkInterfaceId,
+			Ipv6Prefixes:       []string{target},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unassign IPv6 prefix %s from ENI %s: %w", target, *currentENI.NetworkInterfaceId,
+				newAPICallError("UnassignIpv6Addresses", err, *currentENI.NetworkInterfaceId, target))
+		}
+	}
+
+	b.Logger.Printf("Assigning IPv6 prefix %s to ENI %s on instance %s", target, *networkInterfaceID, instanceID)
+	assignOut, err := b.EC2.AssignIpv6Addresses(ctx, &ec2.AssignIpv6AddressesInput{
+		NetworkInterfaceId: networkInterfaceID,
+		Ipv6Prefixes:       []string{target},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assign IPv6 prefix %s to ENI %s: %w", target, *networkInterfaceID,
+			newAPICallError("AssignIpv6Addresses", err, *networkInterfaceID, target))
+	}
+
+	if len(assignOut.AssignedIpv6Prefixes) > 0 {
+		target = assignOut.AssignedIpv6Prefixes[0]
+	}
+
+	b.Logger.Printf("Successfully assigned IPv6 prefix %s to ENI %s on instance %s", target, *networkInterfaceID, instanceID)
+	result := &BindResult{
+		AlreadyAssociated:  false,
+		InstanceID:         instanceID,
+		Family:             IPFamilyIPv6,
+		TargetIP:           target,
 		NetworkInterfaceID: *networkInterfaceID,
-	}, nil
+		Prefix:             true,
+	}
+	if currentENI != nil {
+		result.PreviousNetworkInterfaceID = *currentENI.NetworkInterfaceId
+		result.PreviousInstanceID = attachedInstanceID(currentENI)
+	}
+	return result, nil
 }
 
 func (b *Binder) findPrimaryNetworkInterface(ctx context.Context, instanceID string) (*types.NetworkInterface, error) {
-	eniOut, err := b.EC2.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
+	enis, err := b.describeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
 		Filters: []types.Filter{
 			{
 				Name:   new("attachment.instance-id"),
@@ -267,16 +543,21 @@ func (b *Binder) findPrimaryNetworkInterface(ctx context.Context, instanceID str
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("describe primary network interface for instance %s: %w", instanceID, err)
+		return nil, fmt.Errorf("describe primary network interface for instance %s: %w", instanceID,
+			newAPICallError("DescribeNetworkInterfaces", err, instanceID))
 	}
-	if len(eniOut.NetworkInterfaces) == 0 {
+	switch len(enis) {
+	case 0:
 		return nil, fmt.Errorf("no primary network interface found for instance %s", instanceID)
+	case 1:
+		return &enis[0], nil
+	default:
+		return nil, newAmbiguousMatchError("primary network interfaces for instance "+instanceID, networkInterfaceIDs(enis))
 	}
-	return &eniOut.NetworkInterfaces[0], nil
 }
 
 func (b *Binder) findNetworkInterfaceByIPv6(ctx context.Context, targetIP string) (*types.NetworkInterface, error) {
-	eniOut, err := b.EC2.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
+	enis, err := b.describeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
 		Filters: []types.Filter{
 			{
 				Name:   new("ipv6-addresses.ipv6-address"),
@@ -285,39 +566,126 @@ func (b *Binder) findNetworkInterfaceByIPv6(ctx context.Context, targetIP string
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("describe network interface for IPv6 %s: %w", targetIP, err)
+		return nil, fmt.Errorf("describe network interface for IPv6 %s: %w", targetIP,
+			newAPICallError("DescribeNetworkInterfaces", err, targetIP))
+	}
+	switch len(enis) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &enis[0], nil
+	default:
+		return nil, newAmbiguousMatchError("network interfaces holding IPv6 "+targetIP, networkInterfaceIDs(enis))
+	}
+}
+
+// describeNetworkInterfaces returns every ENI matching input across all pages.
+func (b *Binder) describeNetworkInterfaces(ctx context.Context, input *ec2.DescribeNetworkInterfacesInput) ([]types.NetworkInterface, error) {
+	var enis []types.NetworkInterface
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(b.EC2, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		enis = append(enis, page.NetworkInterfaces...)
+	}
+	return enis, nil
+}
+
+// findNetworkInterfaceByIPv6Prefix scans the ENIs in subnetID for the one
+// holding targetPrefix. EC2 has no DescribeNetworkInterfaces filter for
+// delegated prefixes, but a prefix can only be delegated to ENIs in the subnet
+// it was carved from.
+func (b *Binder) findNetworkInterfaceByIPv6Prefix(ctx context.Context, targetPrefix netip.Prefix, subnetID string) (*types.NetworkInterface, error) {
+	enis, err := b.describeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
+		Filters: []types.Filter{
+			{
+				Name:   new("subnet-id"),
+				Values: []string{subnetID},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describe network interfaces in subnet %s for IPv6 prefix %s: %w", subnetID, targetPrefix,
+			newAPICallError("DescribeNetworkInterfaces", err, subnetID, targetPrefix.String()))
 	}
-	if len(eniOut.NetworkInterfaces) == 0 {
+	var matches []types.NetworkInterface
+	for _, eni := range enis {
+		if hasIPv6Prefix(&eni, targetPrefix) {
+			matches = append(matches, eni)
+		}
+	}
+	switch len(matches) {
+	case 0:
 		return nil, nil
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, newAmbiguousMatchError("network interfaces holding IPv6 prefix "+targetPrefix.String(), networkInterfaceIDs(matches))
 	}
-	return &eniOut.NetworkInterfaces[0], nil
 }
 
 func (b *Binder) ensureIPv6InSubnet(ctx context.Context, targetAddr netip.Addr, subnetID, networkInterfaceID string) error {
+	cidrs, err := b.subnetIPv6CIDRs(ctx, subnetID, networkInterfaceID)
+	if err != nil {
+		return err
+	}
+	for _, cidr := range cidrs {
+		if cidr.Contains(targetAddr) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("IPv6 %s is not in subnet %s IPv6 CIDR blocks", targetAddr.String(), subnetID)
+}
+
+func (b *Binder) ensureIPv6PrefixInSubnet(ctx context.Context, targetPrefix netip.Prefix, subnetID, networkInterfaceID string) error {
+	cidrs, err := b.subnetIPv6CIDRs(ctx, subnetID, networkInterfaceID)
+	if err != nil {
+		return err
+	}
+	for _, cidr := range cidrs {
+		if cidr.Bits() <= targetPrefix.Bits() && cidr.Contains(targetPrefix.Addr()) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("IPv6 prefix %s is not in subnet %s IPv6 CIDR blocks", targetPrefix.String(), subnetID)
+}
+
+func (b *Binder) subnetIPv6CIDRs(ctx context.Context, subnetID, networkInterfaceID string) ([]netip.Prefix, error) {
 	subnetsOut, err := b.EC2.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
 		SubnetIds: []string{subnetID},
 	})
 	if err != nil {
-		return fmt.Errorf("describe subnet %s for ENI %s: %w", subnetID, networkInterfaceID, err)
+		return nil, fmt.Errorf("describe subnet %s for ENI %s: %w", subnetID, networkInterfaceID,
+			newAPICallError("DescribeSubnets", err, subnetID, networkInterfaceID))
 	}
 	if len(subnetsOut.Subnets) == 0 {
-		return fmt.Errorf("subnet %s for ENI %s not found", subnetID, networkInterfaceID)
+		return nil, fmt.Errorf("subnet %s for ENI %s not found", subnetID, networkInterfaceID)
 	}
 
+	var cidrs []netip.Prefix
 	for _, assoc := range subnetsOut.Subnets[0].Ipv6CidrBlockAssociationSet {
 		if assoc.Ipv6CidrBlock == nil {
 			continue
 		}
 		prefix, err := netip.ParsePrefix(*assoc.Ipv6CidrBlock)
 		if err != nil {
-			return fmt.Errorf("parse IPv6 CIDR %s for subnet %s: %w", *assoc.Ipv6CidrBlock, subnetID, err)
-		}
-		if prefix.Contains(targetAddr) {
-			return nil
+			return nil, fmt.Errorf("parse IPv6 CIDR %s for subnet %s: %w", *assoc.Ipv6CidrBlock, subnetID, err)
 		}
+		cidrs = append(cidrs, prefix)
 	}
+	return cidrs, nil
+}
 
-	return fmt.Errorf("IPv6 %s is not in subnet %s IPv6 CIDR blocks", targetAddr.String(), subnetID)
+// attachedInstanceID returns the instance eni is attached to, if any.
+func attachedInstanceID(eni *types.NetworkInterface) string {
+	if eni.Attachment == nil {
+		return ""
+	}
+	return aws.ToString(eni.Attachment.InstanceId)
 }
 
 func hasIPv6(eni *types.NetworkInterface, targetIP string) bool {
@@ -328,3 +696,16 @@ func hasIPv6(eni *types.NetworkInterface, targetIP string) bool {
 	}
 	return false
 }
+
+func hasIPv6Prefix(eni *types.NetworkInterface, targetPrefix netip.Prefix) bool {
+	for _, ipv6Prefix := range eni.Ipv6Prefixes {
+		if ipv6Prefix.Ipv6Prefix == nil {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(*ipv6Prefix.Ipv6Prefix)
+		if err == nil && prefix == targetPrefix {
+			return true
+		}
+	}
+	return false
+}
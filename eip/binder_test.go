@@ -19,7 +19,11 @@ type describeNetworkInterfacesFunc func(*ec2.DescribeNetworkInterfacesInput) (*e
 type associateAddressFunc func(*ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error)
 type assignIPv6AddressesFunc func(*ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error)
 type unassignIPv6AddressesFunc func(*ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error)
+type modifyNetworkInterfaceAttributeFunc func(*ec2.ModifyNetworkInterfaceAttributeInput) (*ec2.ModifyNetworkInterfaceAttributeOutput, error)
 type describeSubnetsFunc func(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
+type describeInstancesFunc func(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
+type disassociateAddressFunc func(*ec2.DisassociateAddressInput) (*ec2.DisassociateAddressOutput, error)
+type describeTagsFunc func(*ec2.DescribeTagsInput) (*ec2.DescribeTagsOutput, error)
 
 type fakeEC2 struct {
 	t *testing.T
@@ -31,7 +35,11 @@ type fakeEC2 struct {
 	associateAddress          associateAddressFunc
 	assignIPv6Addresses       assignIPv6AddressesFunc
 	unassignIPv6Addresses     unassignIPv6AddressesFunc
+	modifyNetworkInterface    modifyNetworkInterfaceAttributeFunc
 	describeSubnets           describeSubnetsFunc
+	describeTags              describeTagsFunc
+	describeInstances         describeInstancesFunc
+	disassociateAddress       disassociateAddressFunc
 }
 
 func newFakeEC2(t *testing.T) *fakeEC2 {
@@ -91,6 +99,16 @@ func (f *fakeEC2) UnassignIpv6Addresses(_ context.Context, in *ec2.UnassignIpv6A
 	return f.unassignIPv6Addresses(in)
 }
 
+func (f *fakeEC2) ModifyNetworkInterfaceAttribute(_ context.Context, in *ec2.ModifyNetworkInterfaceAttributeInput, _ ...func(*ec2.Options)) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+	f.t.Helper()
+	f.record("ModifyNetworkInterfaceAttribute")
+	if f.modifyNetworkInterface == nil {
+		f.unexpected("ModifyNetworkInterfaceAttribute")
+		return nil, nil
+	}
+	return f.modifyNetworkInterface(in)
+}
+
 func (f *fakeEC2) DescribeSubnets(_ context.Context, in *ec2.DescribeSubnetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
 	f.t.Helper()
 	f.record("DescribeSubnets")
@@ -101,6 +119,36 @@ func (f *fakeEC2) DescribeSubnets(_ context.Context, in *ec2.DescribeSubnetsInpu
 	return f.describeSubnets(in)
 }
 
+func (f *fakeEC2) DescribeInstances(_ context.Context, in *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	f.t.Helper()
+	f.record("DescribeInstances")
+	if f.describeInstances == nil {
+		f.unexpected("DescribeInstances")
+		return nil, nil
+	}
+	return f.describeInstances(in)
+}
+
+func (f *fakeEC2) DisassociateAddress(_ context.Context, in *ec2.DisassociateAddressInput, _ ...func(*ec2.Options)) (*ec2.DisassociateAddressOutput, error) {
+	f.t.Helper()
+	f.record("DisassociateAddress")
+	if f.disassociateAddress == nil {
+		f.unexpected("DisassociateAddress")
+		return nil, nil
+	}
+	return f.disassociateAddress(in)
+}
+
+func (f *fakeEC2) DescribeTags(_ context.Context, in *ec2.DescribeTagsInput, _ ...func(*ec2.Options)) (*ec2.DescribeTagsOutput, error) {
+	f.t.Helper()
+	f.record("DescribeTags")
+	if f.describeTags == nil {
+		f.unexpected("DescribeTags")
+		return nil, nil
+	}
+	return f.describeTags(in)
+}
+
 func (f *fakeEC2) record(call string) {
 	f.t.Helper()
 	f.calls = append(f.calls, call)
@@ -202,6 +250,12 @@ func networkInterface(id string) types.NetworkInterface {
 	}
 }
 
+func attachedNetworkInterface(id, instanceID string) types.NetworkInterface {
+	eni := networkInterface(id)
+	eni.Attachment = &types.NetworkInterfaceAttachment{InstanceId: new(instanceID)}
+	return eni
+}
+
 func primaryENI(ipv6s ...string) types.NetworkInterface {
 	addresses := make([]types.NetworkInterfaceIpv6Address, 0, len(ipv6s))
 	for _, ipv6 := range ipv6s {
@@ -217,6 +271,23 @@ func primaryENI(ipv6s ...string) types.NetworkInterface {
 	}
 }
 
+func withPrimaryIPv6(eni types.NetworkInterface, primary string) types.NetworkInterface {
+	eni.Ipv6Addresses = append(eni.Ipv6Addresses, types.NetworkInterfaceIpv6Address{
+		Ipv6Address:   new(primary),
+		IsPrimaryIpv6: new(true),
+	})
+	return eni
+}
+
+func withIPv6Prefixes(eni types.NetworkInterface, prefixes ...string) types.NetworkInterface {
+	for _, prefix := range prefixes {
+		eni.Ipv6Prefixes = append(eni.Ipv6Prefixes, types.Ipv6PrefixSpecification{
+			Ipv6Prefix: new(prefix),
+		})
+	}
+	return eni
+}
+
 func subnetWithIPv6CIDR(cidr string) types.Subnet {
 	return types.Subnet{
 		SubnetId: new("subnet-1"),
@@ -249,6 +320,18 @@ func assertBindResult(t *testing.T, got *BindResult, want BindResult) {
 	if got.NetworkInterfaceID != want.NetworkInterfaceID {
 		t.Errorf("NetworkInterfaceID = %q, want %q", got.NetworkInterfaceID, want.NetworkInterfaceID)
 	}
+	if got.Prefix != want.Prefix {
+		t.Errorf("Prefix = %v, want %v", got.Prefix, want.Prefix)
+	}
+	if got.PrimaryIPv6 != want.PrimaryIPv6 {
+		t.Errorf("PrimaryIPv6 = %v, want %v", got.PrimaryIPv6, want.PrimaryIPv6)
+	}
+	if got.PreviousNetworkInterfaceID != want.PreviousNetworkInterfaceID {
+		t.Errorf("PreviousNetworkInterfaceID = %q, want %q", got.PreviousNetworkInterfaceID, want.PreviousNetworkInterfaceID)
+	}
+	if got.PreviousInstanceID != want.PreviousInstanceID {
+		t.Errorf("PreviousInstanceID = %q, want %q", got.PreviousInstanceID, want.PreviousInstanceID)
+	}
 }
 
 func requireStrings(t *testing.T, got []string, want []string, label string) {
@@ -307,6 +390,11 @@ func requireIPv6ENIFilter(t *testing.T, in *ec2.DescribeNetworkInterfacesInput,
 	requireFilter(t, in.Filters, "ipv6-addresses.ipv6-address", targetIP)
 }
 
+func requireSubnetENIFilter(t *testing.T, in *ec2.DescribeNetworkInterfacesInput, subnetID string) {
+	t.Helper()
+	requireFilter(t, in.Filters, "subnet-id", subnetID)
+}
+
 func requireSubnetInput(t *testing.T, in *ec2.DescribeSubnetsInput, subnetID string) {
 	t.Helper()
 	requireStrings(t, in.SubnetIds, []string{subnetID}, "SubnetIds")
@@ -320,12 +408,13 @@ func instanceMetadata(instanceID string) map[string]string {
 
 func TestGetInstanceID(t *testing.T) {
 	tests := []struct {
-		name     string
-		output   *ec2imds.GetMetadataOutput
-		err      error
-		want     string
-		wantErr  bool
-		wantPath string
+		name       string
+		instanceID string
+		output     *ec2imds.GetMetadataOutput
+		err        error
+		want       string
+		wantErr    bool
+		wantPath   string
 	}{
 		{
 			name: "success",
@@ -355,12 +444,19 @@ func TestGetInstanceID(t *testing.T) {
 			wantErr:  true,
 			wantPath: "instance-id",
 		},
+		{
+			name:       "off-instance override skips metadata",
+			instanceID: "i-other",
+			err:        errors.New("imds unavailable"),
+			want:       "i-other",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var gotPath string
 			binder := &Binder{
+				InstanceID: tt.instanceID,
 				IMDS: metadataClientFunc(func(_ context.Context, in *ec2imds.GetMetadataInput, _ ...func(*ec2imds.Options)) (*ec2imds.GetMetadataOutput, error) {
 					if in != nil {
 						gotPath = in.Path
@@ -466,11 +562,13 @@ func TestBindIPv4Scenarios(t *testing.T) {
 				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
 			},
 			wantResult: &BindResult{
-				AssociationID:      "eipassoc-primary",
-				InstanceID:         instanceID,
-				Family:             IPFamilyIPv4,
-				TargetIP:           targetIP,
-				NetworkInterfaceID: "eni-primary",
+				AssociationID:              "eipassoc-primary",
+				InstanceID:                 instanceID,
+				Family:                     IPFamilyIPv4,
+				TargetIP:                   targetIP,
+				NetworkInterfaceID:         "eni-primary",
+				PreviousNetworkInterfaceID: "eni-secondary",
+				PreviousInstanceID:         instanceID,
 			},
 			wantEC2Calls: []string{"DescribeAddresses", "DescribeNetworkInterfaces", "AssociateAddress"},
 			wantIMDSCalls: []string{
@@ -544,11 +642,12 @@ func TestBindIPv4Scenarios(t *testing.T) {
 				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
 			},
 			wantResult: &BindResult{
-				AssociationID:      "eipassoc-new",
-				InstanceID:         instanceID,
-				Family:             IPFamilyIPv4,
-				TargetIP:           targetIP,
-				NetworkInterfaceID: "eni-primary",
+				AssociationID:              "eipassoc-new",
+				InstanceID:                 instanceID,
+				Family:                     IPFamilyIPv4,
+				TargetIP:                   targetIP,
+				NetworkInterfaceID:         "eni-primary",
+				PreviousNetworkInterfaceID: "eni-old",
 			},
 			wantEC2Calls: []string{"DescribeAddresses", "DescribeNetworkInterfaces", "AssociateAddress"},
 			wantIMDSCalls: []string{
@@ -807,7 +906,7 @@ func TestBindIPv6Scenarios(t *testing.T) {
 					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
 						requireIPv6ENIFilter(t, in, targetIP)
 						return &ec2.DescribeNetworkInterfacesOutput{
-							NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-old")},
+							NetworkInterfaces: []types.NetworkInterface{attachedNetworkInterface("eni-old", "i-old")},
 						}, nil
 					},
 				}
@@ -832,10 +931,12 @@ func TestBindIPv6Scenarios(t *testing.T) {
 				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
 			},
 			wantResult: &BindResult{
-				InstanceID:         instanceID,
-				Family:             IPFamilyIPv6,
-				TargetIP:           "2001:db8::30",
-				NetworkInterfaceID: "eni-primary",
+				InstanceID:                 instanceID,
+				Family:                     IPFamilyIPv6,
+				TargetIP:                   "2001:db8::30",
+				NetworkInterfaceID:         "eni-primary",
+				PreviousNetworkInterfaceID: "eni-old",
+				PreviousInstanceID:         "i-old",
 			},
 			wantEC2Calls: []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces", "UnassignIpv6Addresses", "AssignIpv6Addresses"},
 			wantIMDSCalls: []string{
@@ -984,3 +1085,689 @@ func TestBindIPv6Scenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestBindIPv6PrefixScenarios(t *testing.T) {
+	const instanceID = "i-ipv6-prefix"
+
+	tests := []struct {
+		name          string
+		target        string
+		setup         func(t *testing.T, target string) (*fakeEC2, *fakeIMDS)
+		wantResult    *BindResult
+		wantErr       bool
+		wantEC2Calls  []string
+		wantIMDSCalls []string
+	}{
+		{
+			name:   "already delegated to primary ENI",
+			target: "2001:db8:0:0:1::/80",
+			setup: func(t *testing.T, _ string) (*fakeEC2, *fakeIMDS) {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requirePrimaryENIFilters(t, in, instanceID)
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{withIPv6Prefixes(primaryENI(), "2001:db8:0000:0000:1::/80")},
+						}, nil
+					},
+				}
+				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
+			},
+			wantResult: &BindResult{
+				AlreadyAssociated:  true,
+				InstanceID:         instanceID,
+				Family:             IPFamilyIPv6,
+				TargetIP:           "2001:db8:0:0:1::/80",
+				NetworkInterfaceID: "eni-primary",
+				Prefix:             true,
+			},
+			wantEC2Calls:  []string{"DescribeNetworkInterfaces"},
+			wantIMDSCalls: []string{"GetMetadata:instance-id"},
+		},
+		{
+			name:   "free delegation",
+			target: "2001:db8:0:0:2::/80",
+			setup: func(t *testing.T, target string) (*fakeEC2, *fakeIMDS) {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requirePrimaryENIFilters(t, in, instanceID)
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{primaryENI()},
+						}, nil
+					},
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requireSubnetENIFilter(t, in, "subnet-1")
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{
+								primaryENI(),
+								withIPv6Prefixes(networkInterface("eni-other"), "2001:db8:0:0:3::/80"),
+							},
+						}, nil
+					},
+				}
+				ec2Fake.describeSubnets = func(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					requireSubnetInput(t, in, "subnet-1")
+					return &ec2.DescribeSubnetsOutput{
+						Subnets: []types.Subnet{subnetWithIPv6CIDR("2001:db8::/64")},
+					}, nil
+				}
+				ec2Fake.assignIPv6Addresses = func(in *ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+					requireStringPtr(t, in.NetworkInterfaceId, "eni-primary", "NetworkInterfaceId")
+					requireStrings(t, in.Ipv6Prefixes, []string{target}, "Ipv6Prefixes")
+					if len(in.Ipv6Addresses) != 0 {
+						t.Fatalf("Ipv6Addresses = %v, want none", in.Ipv6Addresses)
+					}
+					return &ec2.AssignIpv6AddressesOutput{
+						AssignedIpv6Prefixes: []string{target},
+					}, nil
+				}
+				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
+			},
+			wantResult: &BindResult{
+				InstanceID:         instanceID,
+				Family:             IPFamilyIPv6,
+				TargetIP:           "2001:db8:0:0:2::/80",
+				NetworkInterfaceID: "eni-primary",
+				Prefix:             true,
+			},
+			wantEC2Calls:  []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces", "AssignIpv6Addresses"},
+			wantIMDSCalls: []string{"GetMetadata:instance-id"},
+		},
+		{
+			name:   "moves from another ENI",
+			target: "2001:db8:0:0:4::/80",
+			setup: func(t *testing.T, target string) (*fakeEC2, *fakeIMDS) {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requirePrimaryENIFilters(t, in, instanceID)
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{primaryENI()},
+						}, nil
+					},
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requireSubnetENIFilter(t, in, "subnet-1")
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{withIPv6Prefixes(networkInterface("eni-old"), target)},
+						}, nil
+					},
+				}
+				ec2Fake.describeSubnets = func(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					requireSubnetInput(t, in, "subnet-1")
+					return &ec2.DescribeSubnetsOutput{
+						Subnets: []types.Subnet{subnetWithIPv6CIDR("2001:db8::/64")},
+					}, nil
+				}
+				ec2Fake.unassignIPv6Addresses = func(in *ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error) {
+					requireStringPtr(t, in.NetworkInterfaceId, "eni-old", "NetworkInterfaceId")
+					requireStrings(t, in.Ipv6Prefixes, []string{target}, "Ipv6Prefixes")
+					return &ec2.UnassignIpv6AddressesOutput{}, nil
+				}
+				ec2Fake.assignIPv6Addresses = func(in *ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+					requireStringPtr(t, in.NetworkInterfaceId, "eni-primary", "NetworkInterfaceId")
+					requireStrings(t, in.Ipv6Prefixes, []string{target}, "Ipv6Prefixes")
+					return &ec2.AssignIpv6AddressesOutput{
+						AssignedIpv6Prefixes: []string{target},
+					}, nil
+				}
+				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
+			},
+			wantResult: &BindResult{
+				InstanceID:                 instanceID,
+				Family:                     IPFamilyIPv6,
+				TargetIP:                   "2001:db8:0:0:4::/80",
+				NetworkInterfaceID:         "eni-primary",
+				PreviousNetworkInterfaceID: "eni-old",
+				Prefix:                     true,
+			},
+			wantEC2Calls:  []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces", "UnassignIpv6Addresses", "AssignIpv6Addresses"},
+			wantIMDSCalls: []string{"GetMetadata:instance-id"},
+		},
+		{
+			name:   "prefix outside primary subnet fails before lookup",
+			target: "2001:db8:2::/80",
+			setup: func(t *testing.T, _ string) (*fakeEC2, *fakeIMDS) {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requirePrimaryENIFilters(t, in, instanceID)
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{primaryENI()},
+						}, nil
+					},
+				}
+				ec2Fake.describeSubnets = func(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					requireSubnetInput(t, in, "subnet-1")
+					return &ec2.DescribeSubnetsOutput{
+						Subnets: []types.Subnet{subnetWithIPv6CIDR("2001:db8:1::/64")},
+					}, nil
+				}
+				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
+			},
+			wantErr:       true,
+			wantEC2Calls:  []string{"DescribeNetworkInterfaces", "DescribeSubnets"},
+			wantIMDSCalls: []string{"GetMetadata:instance-id"},
+		},
+		{
+			name:   "unassign error",
+			target: "2001:db8:0:0:5::/80",
+			setup: func(t *testing.T, target string) (*fakeEC2, *fakeIMDS) {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requirePrimaryENIFilters(t, in, instanceID)
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{primaryENI()},
+						}, nil
+					},
+					func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						requireSubnetENIFilter(t, in, "subnet-1")
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{withIPv6Prefixes(networkInterface("eni-old"), target)},
+						}, nil
+					},
+				}
+				ec2Fake.describeSubnets = func(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					requireSubnetInput(t, in, "subnet-1")
+					return &ec2.DescribeSubnetsOutput{
+						Subnets: []types.Subnet{subnetWithIPv6CIDR("2001:db8::/64")},
+					}, nil
+				}
+				ec2Fake.unassignIPv6Addresses = func(in *ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error) {
+					requireStrings(t, in.Ipv6Prefixes, []string{target}, "Ipv6Prefixes")
+					return nil, errors.New("unassign denied")
+				}
+				return ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID))
+			},
+			wantErr:       true,
+			wantEC2Calls:  []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces", "UnassignIpv6Addresses"},
+			wantIMDSCalls: []string{"GetMetadata:instance-id"},
+		},
+		{
+			name:   "rejects non-/80 prefix before AWS calls",
+			target: "2001:db8::/64",
+			setup: func(t *testing.T, _ string) (*fakeEC2, *fakeIMDS) {
+				return newFakeEC2(t), newFakeIMDS(t, nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake, imdsFake := tt.setup(t, tt.target)
+			result, err := NewBinder(ec2Fake, imdsFake, silentLogger()).Bind(context.Background(), tt.target)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else {
+				assertBindResult(t, result, *tt.wantResult)
+			}
+
+			ec2Fake.assertCalls(tt.wantEC2Calls)
+			imdsFake.assertCalls(tt.wantIMDSCalls)
+		})
+	}
+}
+
+func TestBindAutoIPv6Scenarios(t *testing.T) {
+	const instanceID = "i-auto-ipv6"
+
+	tests := []struct {
+		name         string
+		assign       assignIPv6AddressesFunc
+		wantResult   *BindResult
+		wantErr      bool
+		wantEC2Calls []string
+	}{
+		{
+			name: "EC2 picks the address",
+			assign: func(in *ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+				return &ec2.AssignIpv6AddressesOutput{
+					AssignedIpv6Addresses: []string{"2001:db8::abcd"},
+				}, nil
+			},
+			wantResult: &BindResult{
+				InstanceID:         instanceID,
+				Family:             IPFamilyIPv6,
+				TargetIP:           "2001:db8::abcd",
+				NetworkInterfaceID: "eni-primary",
+			},
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "AssignIpv6Addresses"},
+		},
+		{
+			name: "assign error",
+			assign: func(*ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+				return nil, errors.New("subnet full")
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "AssignIpv6Addresses"},
+		},
+		{
+			name: "no address returned",
+			assign: func(*ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+				return &ec2.AssignIpv6AddressesOutput{}, nil
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "AssignIpv6Addresses"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := newFakeEC2(t)
+			ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+				func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+					requirePrimaryENIFilters(t, in, instanceID)
+					return &ec2.DescribeNetworkInterfacesOutput{
+						NetworkInterfaces: []types.NetworkInterface{primaryENI("2001:db8::1")},
+					}, nil
+				},
+			}
+			ec2Fake.assignIPv6Addresses = func(in *ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+				requireStringPtr(t, in.NetworkInterfaceId, "eni-primary", "NetworkInterfaceId")
+				if in.Ipv6AddressCount == nil || *in.Ipv6AddressCount != 1 {
+					t.Fatalf("Ipv6AddressCount = %v, want 1", in.Ipv6AddressCount)
+				}
+				if len(in.Ipv6Addresses) != 0 {
+					t.Fatalf("Ipv6Addresses = %v, want none", in.Ipv6Addresses)
+				}
+				return tt.assign(in)
+			}
+			imdsFake := newFakeIMDS(t, instanceMetadata(instanceID))
+
+			result, err := NewBinder(ec2Fake, imdsFake, silentLogger()).Bind(context.Background(), AutoIPv6)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else {
+				assertBindResult(t, result, *tt.wantResult)
+			}
+
+			ec2Fake.assertCalls(tt.wantEC2Calls)
+			imdsFake.assertCalls([]string{"GetMetadata:instance-id"})
+		})
+	}
+}
+
+func TestBindIPv6PrimaryScenarios(t *testing.T) {
+	const (
+		instanceID = "i-primary"
+		targetIP   = "2001:db8::70"
+	)
+
+	describePrimary := func(t *testing.T, eni types.NetworkInterface) describeNetworkInterfacesFunc {
+		return func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, instanceID)
+			return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: []types.NetworkInterface{eni}}, nil
+		}
+	}
+	describeByID := func(t *testing.T, eni types.NetworkInterface) describeNetworkInterfacesFunc {
+		return func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requireStrings(t, in.NetworkInterfaceIds, []string{"eni-primary"}, "NetworkInterfaceIds")
+			return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: []types.NetworkInterface{eni}}, nil
+		}
+	}
+	noHolder := func(t *testing.T) describeNetworkInterfacesFunc {
+		return func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requireIPv6ENIFilter(t, in, targetIP)
+			return &ec2.DescribeNetworkInterfacesOutput{}, nil
+		}
+	}
+	otherHolder := func(t *testing.T) describeNetworkInterfacesFunc {
+		return func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requireIPv6ENIFilter(t, in, targetIP)
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-other")},
+			}, nil
+		}
+	}
+	subnet := func(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+		return &ec2.DescribeSubnetsOutput{Subnets: []types.Subnet{subnetWithIPv6CIDR("2001:db8::/64")}}, nil
+	}
+	assign := func(*ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+		return &ec2.AssignIpv6AddressesOutput{AssignedIpv6Addresses: []string{targetIP}}, nil
+	}
+
+	tests := []struct {
+		name         string
+		mode         PrimaryIPv6Mode
+		setup        func(t *testing.T) *fakeEC2
+		wantResult   *BindResult
+		wantErr      bool
+		wantEC2Calls []string
+	}{
+		{
+			name: "ignore mode reports known primary without extra calls",
+			mode: PrimaryIPv6Ignore,
+			setup: func(t *testing.T) *fakeEC2 {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describePrimary(t, withPrimaryIPv6(primaryENI(), targetIP)),
+				}
+				return ec2Fake
+			},
+			wantResult: &BindResult{
+				AlreadyAssociated:  true,
+				InstanceID:         instanceID,
+				Family:             IPFamilyIPv6,
+				TargetIP:           targetIP,
+				NetworkInterfaceID: "eni-primary",
+				PrimaryIPv6:        true,
+			},
+			wantEC2Calls: []string{"DescribeNetworkInterfaces"},
+		},
+		{
+			name: "verify fails when already assigned but not primary",
+			mode: PrimaryIPv6Verify,
+			setup: func(t *testing.T) *fakeEC2 {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describePrimary(t, withPrimaryIPv6(primaryENI(targetIP), "2001:db8::1")),
+				}
+				return ec2Fake
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeNetworkInterfaces"},
+		},
+		{
+			name: "verify fails before moving address to ENI with another primary",
+			mode: PrimaryIPv6Verify,
+			setup: func(t *testing.T) *fakeEC2 {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describePrimary(t, withPrimaryIPv6(primaryENI(), "2001:db8::1")),
+					otherHolder(t),
+				}
+				ec2Fake.describeSubnets = subnet
+				return ec2Fake
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces"},
+		},
+		{
+			name: "verify fails before moving address to ENI without a primary",
+			mode: PrimaryIPv6Verify,
+			setup: func(t *testing.T) *fakeEC2 {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describePrimary(t, primaryENI()),
+					otherHolder(t),
+				}
+				ec2Fake.describeSubnets = subnet
+				return ec2Fake
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces"},
+		},
+		{
+			name: "request fails before moving address to ENI with other IPv6 addresses",
+			mode: PrimaryIPv6Request,
+			setup: func(t *testing.T) *fakeEC2 {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describePrimary(t, primaryENI("2001:db8::5")),
+					otherHolder(t),
+				}
+				ec2Fake.describeSubnets = subnet
+				return ec2Fake
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces"},
+		},
+		{
+			name: "request enables primary IPv6 on ENI without one",
+			mode: PrimaryIPv6Request,
+			setup: func(t *testing.T) *fakeEC2 {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describePrimary(t, primaryENI()),
+					noHolder(t),
+					describeByID(t, primaryENI(targetIP)),
+					describeByID(t, withPrimaryIPv6(primaryENI(), targetIP)),
+				}
+				ec2Fake.describeSubnets = subnet
+				ec2Fake.assignIPv6Addresses = assign
+				ec2Fake.modifyNetworkInterface = func(in *ec2.ModifyNetworkInterfaceAttributeInput) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+					requireStringPtr(t, in.NetworkInterfaceId, "eni-primary", "NetworkInterfaceId")
+					requireBoolPtr(t, in.EnablePrimaryIpv6, true, "EnablePrimaryIpv6")
+					return &ec2.ModifyNetworkInterfaceAttributeOutput{}, nil
+				}
+				return ec2Fake
+			},
+			wantResult: &BindResult{
+				InstanceID:         instanceID,
+				Family:             IPFamilyIPv6,
+				TargetIP:           targetIP,
+				NetworkInterfaceID: "eni-primary",
+				PrimaryIPv6:        true,
+			},
+			wantEC2Calls: []string{
+				"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces", "AssignIpv6Addresses",
+				"DescribeNetworkInterfaces", "ModifyNetworkInterfaceAttribute", "DescribeNetworkInterfaces",
+			},
+		},
+		{
+			name: "request fails when ENI already has another primary",
+			mode: PrimaryIPv6Request,
+			setup: func(t *testing.T) *fakeEC2 {
+				ec2Fake := newFakeEC2(t)
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describePrimary(t, primaryENI()),
+					noHolder(t),
+					describeByID(t, withPrimaryIPv6(primaryENI(targetIP), "2001:db8::1")),
+				}
+				ec2Fake.describeSubnets = subnet
+				ec2Fake.assignIPv6Addresses = assign
+				return ec2Fake
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "DescribeSubnets", "DescribeNetworkInterfaces", "AssignIpv6Addresses", "DescribeNetworkInterfaces"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := tt.setup(t)
+			imdsFake := newFakeIMDS(t, instanceMetadata(instanceID))
+			binder := NewBinder(ec2Fake, imdsFake, silentLogger())
+			binder.PrimaryIPv6 = tt.mode
+
+			result, err := binder.Bind(context.Background(), targetIP)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else {
+				assertBindResult(t, result, *tt.wantResult)
+			}
+
+			ec2Fake.assertCalls(tt.wantEC2Calls)
+			imdsFake.assertCalls([]string{"GetMetadata:instance-id"})
+		})
+	}
+}
+
+func TestBindIPv4UsesAddressEC2(t *testing.T) {
+	const (
+		targetIP   = "54.162.153.80"
+		instanceID = "i-ipv4"
+	)
+
+	addressFake := newFakeEC2(t)
+	addressFake.describeAddresses = func(in *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		requireDescribeAddressInput(t, in, targetIP)
+		return &ec2.DescribeAddressesOutput{
+			Addresses: []types.Address{elasticAddress(targetIP, "eipalloc-111", "")},
+		}, nil
+	}
+	addressFake.associateAddress = func(in *ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+		requireStringPtr(t, in.NetworkInterfaceId, "eni-primary", "NetworkInterfaceId")
+		return &ec2.AssociateAddressOutput{AssociationId: new("eipassoc-111")}, nil
+	}
+	eniFake := newFakeEC2(t)
+	eniFake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, instanceID)
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+	}
+
+	binder := NewBinder(eniFake, newFakeIMDS(t, instanceMetadata(instanceID)), silentLogger())
+	binder.AddressEC2 = addressFake
+	if _, err := binder.Bind(context.Background(), targetIP); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addressFake.assertCalls([]string{"DescribeAddresses", "AssociateAddress"})
+	eniFake.assertCalls([]string{"DescribeNetworkInterfaces"})
+}
+
+func TestBindFollowsNetworkInterfacePages(t *testing.T) {
+	const (
+		targetIP   = "2001:db8::10"
+		instanceID = "i-ipv6"
+	)
+
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, instanceID)
+			if in.NextToken != nil {
+				t.Fatalf("first page NextToken = %q, want nil", *in.NextToken)
+			}
+			return &ec2.DescribeNetworkInterfacesOutput{NextToken: new("page-2")}, nil
+		},
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, instanceID)
+			requireStringPtr(t, in.NextToken, "page-2", "NextToken")
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{primaryENI(targetIP)},
+			}, nil
+		},
+	}
+
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID)), silentLogger())
+	got, err := binder.Bind(context.Background(), targetIP)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertBindResult(t, got, BindResult{
+		AlreadyAssociated:  true,
+		InstanceID:         instanceID,
+		Family:             IPFamilyIPv6,
+		TargetIP:           targetIP,
+		NetworkInterfaceID: "eni-primary",
+	})
+	ec2Fake.assertCalls([]string{"DescribeNetworkInterfaces", "DescribeNetworkInterfaces"})
+}
+
+func TestBindAmbiguousMatches(t *testing.T) {
+	const instanceID = "i-ambiguous"
+
+	primaryPage := func(enis ...types.NetworkInterface) describeNetworkInterfacesFunc {
+		return func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: enis}, nil
+		}
+	}
+
+	tests := []struct {
+		name           string
+		targetIP       string
+		setup          func(ec2Fake *fakeEC2)
+		wantCandidates []string
+	}{
+		{
+			name:     "Elastic IP allocations",
+			targetIP: "54.162.153.80",
+			setup: func(ec2Fake *fakeEC2) {
+				ec2Fake.describeAddresses = func(*ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+					return &ec2.DescribeAddressesOutput{Addresses: []types.Address{
+						elasticAddress("54.162.153.80", "eipalloc-222", ""),
+						elasticAddress("54.162.153.80", "eipalloc-111", ""),
+					}}, nil
+				}
+			},
+			wantCandidates: []string{"eipalloc-111", "eipalloc-222"},
+		},
+		{
+			name:     "primary ENIs across pages",
+			targetIP: "2001:db8::10",
+			setup: func(ec2Fake *fakeEC2) {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-b")},
+							NextToken:         new("page-2"),
+						}, nil
+					},
+					primaryPage(networkInterface("eni-a")),
+				}
+			},
+			wantCandidates: []string{"eni-a", "eni-b"},
+		},
+		{
+			name:     "ENIs holding the IPv6 address",
+			targetIP: "2001:db8::10",
+			setup: func(ec2Fake *fakeEC2) {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					primaryPage(primaryENI()),
+					primaryPage(networkInterface("eni-other-2"), networkInterface("eni-other-1")),
+				}
+				ec2Fake.describeSubnets = func(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					return &ec2.DescribeSubnetsOutput{Subnets: []types.Subnet{subnetWithIPv6CIDR("2001:db8::/64")}}, nil
+				}
+			},
+			wantCandidates: []string{"eni-other-1", "eni-other-2"},
+		},
+		{
+			name:     "ENIs holding the IPv6 prefix",
+			targetIP: "2001:db8:0:0:1::/80",
+			setup: func(ec2Fake *fakeEC2) {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					primaryPage(primaryENI()),
+					func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{withIPv6Prefixes(networkInterface("eni-other-2"), "2001:db8:0:0:1::/80")},
+							NextToken:         new("page-2"),
+						}, nil
+					},
+					primaryPage(withIPv6Prefixes(networkInterface("eni-other-1"), "2001:db8:0:0:1::/80")),
+				}
+				ec2Fake.describeSubnets = func(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					return &ec2.DescribeSubnetsOutput{Subnets: []types.Subnet{subnetWithIPv6CIDR("2001:db8::/64")}}, nil
+				}
+			},
+			wantCandidates: []string{"eni-other-1", "eni-other-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := newFakeEC2(t)
+			tt.setup(ec2Fake)
+			binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID)), silentLogger())
+
+			_, err := binder.Bind(context.Background(), tt.targetIP)
+			ambiguous, ok := errors.AsType[*AmbiguousMatchError](err)
+			if !ok {
+				t.Fatalf("error = %v, want AmbiguousMatchError", err)
+			}
+			requireStrings(t, ambiguous.Candidates, tt.wantCandidates, "Candidates")
+			if !strings.Contains(err.Error(), strings.Join(tt.wantCandidates, ", ")) {
+				t.Fatalf("error %q does not list the candidates", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+// CheckStatus is the outcome of one pre-flight check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "PASS"
+	CheckFail CheckStatus = "FAIL"
+	// CheckError means the call failed for a reason other than missing
+	// permissions, so the permission is unknown.
+	CheckError CheckStatus = "ERROR"
+	// CheckSkip means the permission cannot be exercised safely, for example
+	// because the API has no DryRun mode.
+	CheckSkip CheckStatus = "SKIP"
+)
+
+// CheckResult is one row of the pre-flight report.
+type CheckResult struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+}
+
+// Checker exercises the permissions a configuration needs without changing
+// anything, using EC2 DryRun requests.
+type Checker struct {
+	EC2  EC2API
+	IMDS MetadataClient
+	// AddressEC2, when set, is used for Elastic IP calls as in Binder.
+	AddressEC2 EC2API
+	// InstanceID, when set, is checked instead of the current instance.
+	InstanceID string
+}
+
+// checkPlaceholders stand in for resource IDs that could not be looked up.
+// EC2 checks permissions before resolving IDs on most DryRun requests.
+const (
+	placeholderInstanceID    = "i-00000000000000000"
+	placeholderENIID         = "eni-00000000000000000"
+	placeholderSubnetID      = "subnet-00000000000000000"
+	placeholderAllocationID  = "eipalloc-00000000000000000"
+	placeholderAssociationID = "eipassoc-00000000000000000"
+)
+
+// checkTarget holds the resource IDs the DryRun requests are made against.
+type checkTarget struct {
+	instanceID    string
+	eniID         string
+	subnetID      string
+	allocationID  string
+	associationID string
+}
+
+// Check verifies instance metadata is reachable, unless InstanceID is set,
+// and exercises each EC2 permission in RequiredPermissions(cfg). Permissions
+// of other services are reported as skipped.
+func (c *Checker) Check(ctx context.Context, cfg *Config) []CheckResult {
+	target := checkTarget{
+		instanceID:    c.InstanceID,
+		eniID:         placeholderENIID,
+		subnetID:      placeholderSubnetID,
+		allocationID:  placeholderAllocationID,
+		associationID: placeholderAssociationID,
+	}
+
+	results := []CheckResult{c.checkMetadata(ctx, &target)}
+	if target.instanceID == "" {
+		target.instanceID = placeholderInstanceID
+	}
+	c.lookupTarget(ctx, cfg, &target)
+
+	for _, perm := range RequiredPermissions(cfg) {
+		results = append(results, c.checkPermission(ctx, cfg, perm, target))
+	}
+	return results
+}
+
+// checkMetadata reads the current instance ID from instance metadata. With
+// an explicit InstanceID, as when checking from CI, metadata is not needed
+// and the row is skipped.
+func (c *Checker) checkMetadata(ctx context.Context, target *checkTarget) CheckResult {
+	result := CheckResult{Name: "instance metadata", Status: CheckPass}
+	if c.InstanceID != "" {
+		result.Status, result.Detail = CheckSkip, "not needed; checking instance "+c.InstanceID
+		return result
+	}
+	instanceID, err := CurrentInstanceID(ctx, c.IMDS)
+	if err != nil {
+		result.Status, result.Detail = CheckFail, err.Error()
+		return result
+	}
+	result.Detail = "instance " + instanceID
+	target.instanceID = instanceID
+	return result
+}
+
+// lookupTarget fills in the real resource IDs where the lookups are allowed.
+// Failures leave the placeholders; the DryRun rows report them.
+func (c *Checker) lookupTarget(ctx context.Context, cfg *Config, target *checkTarget) {
+	binder := &Binder{EC2: c.EC2}
+	if eni, err := binder.findPrimaryNetworkInterface(ctx, target.instanceID); err == nil {
+		if eni.NetworkInterfaceId != nil {
+			target.eniID = *eni.NetworkInterfaceId
+		}
+		if eni.SubnetId != nil {
+			target.subnetID = *eni.SubnetId
+		}
+	}
+	if cfg.Family != IPFamilyIPv4 || cfg.TargetRef != "" {
+		return
+	}
+	out, err := c.addressEC2().DescribeAddresses(ctx, &ec2.DescribeAddressesInput{PublicIps: []string{cfg.TargetIP}})
+	if err != nil || len(out.Addresses) != 1 {
+		return
+	}
+	if id := out.Addresses[0].AllocationId; id != nil {
+		target.allocationID = *id
+	}
+	if id := out.Addresses[0].AssociationId; id != nil {
+		target.associationID = *id
+	}
+}
+
+func (c *Checker) addressEC2() EC2API {
+	if c.AddressEC2 != nil {
+		return c.AddressEC2
+	}
+	return c.EC2
+}
+
+func (c *Checker) checkPermission(ctx context.Context, cfg *Config, perm Permission, target checkTarget) CheckResult {
+	client := c.EC2
+	if perm.Address {
+		client = c.addressEC2()
+	}
+
+	var err error
+	switch perm.Action {
+	case "ec2:DescribeNetworkInterfaces":
+		_, err = client.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
+			DryRun:              new(true),
+			NetworkInterfaceIds: []string{target.eniID},
+		})
+	case "ec2:DescribeAddresses":
+		input := &ec2.DescribeAddressesInput{DryRun: new(true)}
+		if cfg.Family == IPFamilyIPv4 && cfg.TargetRef == "" {
+			input.PublicIps = []string{cfg.TargetIP}
+		}
+		_, err = client.DescribeAddresses(ctx, input)
+	case "ec2:AssociateAddress":
+		_, err = client.AssociateAddress(ctx, &ec2.AssociateAddressInput{
+			DryRun:             new(true),
+			AllocationId:       new(target.allocationID),
+			AllowReassociation: new(true),
+			NetworkInterfaceId: new(target.eniID),
+		})
+	case "ec2:DisassociateAddress":
+		_, err = client.DisassociateAddress(ctx, &ec2.DisassociateAddressInput{
+			DryRun:        new(true),
+			AssociationId: new(target.associationID),
+		})
+	case "ec2:DescribeSubnets":
+		_, err = client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+			DryRun:    new(true),
+			SubnetIds: []string{target.subnetID},
+		})
+	case "ec2:ModifyNetworkInterfaceAttribute":
+		_, err = client.ModifyNetworkInterfaceAttribute(ctx, &ec2.ModifyNetworkInterfaceAttributeInput{
+			DryRun:             new(true),
+			NetworkInterfaceId: new(target.eniID),
+			EnablePrimaryIpv6:  new(true),
+		})
+	case "ec2:DescribeInstances":
+		_, err = client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+			DryRun:      new(true),
+			InstanceIds: []string{target.instanceID},
+		})
+	case "ec2:DescribeTags":
+		_, err = client.DescribeTags(ctx, &ec2.DescribeTagsInput{
+			DryRun: new(true),
+			Filters: []types.Filter{
+				{
+					Name:   new("resource-id"),
+					Values: []string{target.instanceID},
+				},
+			},
+		})
+	case "ec2:AssignIpv6Addresses", "ec2:UnassignIpv6Addresses":
+		return CheckResult{Name: perm.Action, Status: CheckSkip, Detail: "no DryRun support; needed to " + perm.Reason}
+	default:
+		return CheckResult{Name: perm.Action, Status: CheckSkip, Detail: "not checked; needed to " + perm.Reason}
+	}
+	return dryRunResult(perm, err)
+}
+
+// dryRunResult classifies the error of a DryRun request. EC2 answers an
+// authorized DryRun request with the DryRunOperation error code.
+func dryRunResult(perm Permission, err error) CheckResult {
+	result := CheckResult{Name: perm.Action}
+	apiErr, isAPIErr := errors.AsType[smithy.APIError](err)
+	switch {
+	case err == nil:
+		result.Status, result.Detail = CheckPass, perm.Reason
+	case isAPIErr && apiErr.ErrorCode() == "DryRunOperation":
+		result.Status, result.Detail = CheckPass, perm.Reason
+	case isAPIErr && (apiErr.ErrorCode() == "UnauthorizedOperation" || strings.HasPrefix(apiErr.ErrorCode(), "AccessDenied")):
+		result.Status, result.Detail = CheckFail, fmt.Sprintf("denied; needed to %s", perm.Reason)
+	default:
+		result.Status, result.Detail = CheckError, err.Error()
+	}
+	return result
+}
+
+// CheckPassed reports whether no check failed or errored.
+func CheckPassed(results []CheckResult) bool {
+	for _, result := range results {
+		if result.Status == CheckFail || result.Status == CheckError {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteCheckTable writes results as an aligned table.
+func WriteCheckTable(w io.Writer, results []CheckResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, result.Status, result.Detail)
+	}
+	return tw.Flush()
+}
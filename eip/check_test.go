@@ -0,0 +1,169 @@
+package eip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	errDryRunOperation = &smithy.GenericAPIError{Code: "DryRunOperation", Message: "Request would have succeeded, but DryRun flag is set."}
+	errUnauthorized    = &smithy.GenericAPIError{Code: "UnauthorizedOperation", Message: "You are not authorized to perform this operation."}
+)
+
+func TestCheckerIPv4(t *testing.T) {
+	const (
+		targetIP   = "54.162.153.80"
+		instanceID = "i-check"
+	)
+
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, instanceID)
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requireBoolPtr(t, in.DryRun, true, "DryRun")
+			requireStrings(t, in.NetworkInterfaceIds, []string{"eni-primary"}, "NetworkInterfaceIds")
+			return nil, errDryRunOperation
+		},
+	}
+	addressFake := newFakeEC2(t)
+	describeCalls := 0
+	addressFake.describeAddresses = func(in *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		requireDescribeAddressInput(t, in, targetIP)
+		describeCalls++
+		if describeCalls == 1 {
+			return &ec2.DescribeAddressesOutput{
+				Addresses: []types.Address{elasticAddress(targetIP, "eipalloc-111", "")},
+			}, nil
+		}
+		requireBoolPtr(t, in.DryRun, true, "DryRun")
+		return nil, errDryRunOperation
+	}
+	addressFake.associateAddress = func(in *ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+		requireBoolPtr(t, in.DryRun, true, "DryRun")
+		requireStringPtr(t, in.AllocationId, "eipalloc-111", "AllocationId")
+		requireStringPtr(t, in.NetworkInterfaceId, "eni-primary", "NetworkInterfaceId")
+		return nil, errUnauthorized
+	}
+
+	checker := &Checker{EC2: ec2Fake, AddressEC2: addressFake, IMDS: newFakeIMDS(t, instanceMetadata(instanceID))}
+	results := checker.Check(context.Background(), &Config{TargetIP: targetIP, Family: IPFamilyIPv4})
+
+	want := []CheckResult{
+		{Name: "instance metadata", Status: CheckPass},
+		{Name: "ec2:AssociateAddress", Status: CheckFail},
+		{Name: "ec2:DescribeAddresses", Status: CheckPass},
+		{Name: "ec2:DescribeNetworkInterfaces", Status: CheckPass},
+	}
+	assertCheckStatuses(t, results, want)
+	if CheckPassed(results) {
+		t.Fatal("CheckPassed = true, want false")
+	}
+	ec2Fake.assertCalls([]string{"DescribeNetworkInterfaces", "DescribeNetworkInterfaces"})
+	addressFake.assertCalls([]string{"DescribeAddresses", "AssociateAddress", "DescribeAddresses"})
+}
+
+func TestCheckerIPv6WithoutMetadata(t *testing.T) {
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, placeholderInstanceID)
+			return nil, errUnauthorized
+		},
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requireStrings(t, in.NetworkInterfaceIds, []string{placeholderENIID}, "NetworkInterfaceIds")
+			return nil, errUnauthorized
+		},
+	}
+	ec2Fake.describeSubnets = func(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+		requireStrings(t, in.SubnetIds, []string{placeholderSubnetID}, "SubnetIds")
+		return nil, errors.New("connection reset")
+	}
+	imdsFake := newFakeIMDS(t, nil)
+	imdsFake.metadataErr = map[string]error{"instance-id": errors.New("no route to host")}
+
+	checker := &Checker{EC2: ec2Fake, IMDS: imdsFake}
+	results := checker.Check(context.Background(), &Config{TargetIP: "2001:db8::1", Family: IPFamilyIPv6})
+
+	want := []CheckResult{
+		{Name: "instance metadata", Status: CheckFail},
+		{Name: "ec2:AssignIpv6Addresses", Status: CheckSkip},
+		{Name: "ec2:DescribeNetworkInterfaces", Status: CheckFail},
+		{Name: "ec2:DescribeSubnets", Status: CheckError},
+		{Name: "ec2:UnassignIpv6Addresses", Status: CheckSkip},
+	}
+	assertCheckStatuses(t, results, want)
+
+	var table bytes.Buffer
+	if err := WriteCheckTable(&table, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range []string{"CHECK", "ec2:DescribeSubnets", "ERROR", "connection reset"} {
+		if !strings.Contains(table.String(), line) {
+			t.Errorf("table does not contain %q:\n%s", line, table.String())
+		}
+	}
+}
+
+func TestCheckerExplicitInstanceWithoutMetadata(t *testing.T) {
+	const instanceID = "i-explicit"
+
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, instanceID)
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requireBoolPtr(t, in.DryRun, true, "DryRun")
+			return nil, errDryRunOperation
+		},
+	}
+	ec2Fake.describeSubnets = func(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+		requireBoolPtr(t, in.DryRun, true, "DryRun")
+		return nil, errDryRunOperation
+	}
+	imdsFake := newFakeIMDS(t, nil)
+	imdsFake.metadataErr = map[string]error{"instance-id": errors.New("no route to host")}
+
+	checker := &Checker{EC2: ec2Fake, IMDS: imdsFake, InstanceID: instanceID}
+	results := checker.Check(context.Background(), &Config{TargetIP: "2001:db8::1", Family: IPFamilyIPv6})
+
+	want := []CheckResult{
+		{Name: "instance metadata", Status: CheckSkip},
+		{Name: "ec2:AssignIpv6Addresses", Status: CheckSkip},
+		{Name: "ec2:DescribeNetworkInterfaces", Status: CheckPass},
+		{Name: "ec2:DescribeSubnets", Status: CheckPass},
+		{Name: "ec2:UnassignIpv6Addresses", Status: CheckSkip},
+	}
+	assertCheckStatuses(t, results, want)
+	if !CheckPassed(results) {
+		t.Fatal("CheckPassed = false, want true")
+	}
+	imdsFake.assertCalls(nil)
+}
+
+func assertCheckStatuses(t *testing.T, got []CheckResult, want []CheckResult) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("results = %+v, want %d rows", got, len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Status != want[i].Status {
+			t.Errorf("result %d = %s %s (%s), want %s %s", i, got[i].Name, got[i].Status, got[i].Detail, want[i].Name, want[i].Status)
+		}
+	}
+}
@@ -1,34 +1,431 @@
 package eip
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"net/netip"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"time"
 )
 
+const usageLine = "usage: aws-eip-binding [flags] <EIP>"
+
+// PodAnnotationTarget is the CLI target that defers resolution to the
+// running pod's own annotation, read through the Kubernetes API.
+const PodAnnotationTarget = "POD_ANNOTATION"
+
 // Config holds the resolved configuration for EIP binding.
 type Config struct {
-	// TargetIP is the IPv4 Elastic IP address or IPv6 address to associate.
+	// TargetIP is the IPv4 Elastic IP address, IPv6 address, or delegated IPv6
+	// prefix (CIDR notation) to associate. It is AutoIPv6 when EC2 should pick
+	// a new IPv6 address.
 	TargetIP string
 	// Family is the address family: "ipv4" or "ipv6".
 	Family string
+	// TargetRef names a target that can only be resolved at runtime, such as
+	// PodAnnotationTarget or an SSM parameter. TargetIP and Family stay empty
+	// until SetTarget is called with the resolved value.
+	TargetRef string
+	// IPv6StateFile persists the address EC2 picked in AutoIPv6 mode so later
+	// runs rebind the same address. Empty disables persistence.
+	IPv6StateFile string
+	// PrimaryIPv6 controls whether the bound IPv6 address must be, or is made,
+	// the ENI's primary IPv6 address.
+	PrimaryIPv6 PrimaryIPv6Mode
+	// ConfigureOS adds the bound address to the local interface backing the ENI.
+	ConfigureOS bool
+	// AddressPool is set for POD_ORDINAL targets drawn from tagged Elastic
+	// IPs. TargetRef is PodOrdinalTarget until ResolveAddressPool's result is
+	// passed to SetTarget.
+	AddressPool *AddressPool
+	// TargetFile is the file a TargetFilePrefix ("@PATH") target was read
+	// from.
+	TargetFile string
+	// Watch keeps running after the first bind and rebinds whenever
+	// TargetFile changes.
+	Watch bool
+	// WatchInterval is how often TargetFile is re-read in watch mode.
+	WatchInterval time.Duration
+	// InstanceTag is the tag key read for InstanceTagTarget.
+	InstanceTag string
+	// Instance selects another instance to bind to instead of the one running
+	// the binary (off-instance mode). Nil binds to the current instance.
+	Instance *InstanceSelector
+	// OnInterruption, when set, keeps the process running after binding and
+	// releases or hands off the address on a spot interruption notice,
+	// rebalance recommendation, or SIGTERM.
+	OnInterruption *InterruptionAction
+	// InterruptionInterval is how often instance metadata is polled for
+	// interruption notices.
+	InterruptionInterval time.Duration
+	// Role is assumed for all EC2 calls. Nil uses the default credentials.
+	Role *AssumeRole
+	// AddressRole is assumed for Elastic IP calls only, when addresses live in
+	// another account than the instance's network interfaces.
+	AddressRole *AssumeRole
+	// IMDS configures the instance metadata client used by the binder and by
+	// the SDK's instance role credentials.
+	IMDS IMDSOptions
+	// NetworkStack selects IPv4, IPv6, or dual-stack IMDS and EC2 endpoints.
+	// NetworkStackAuto must be replaced with DetectNetworkStack's result
+	// before clients are built.
+	NetworkStack NetworkStack
+	// EC2Endpoint overrides the EC2 API endpoint URL, for example a VPC
+	// interface endpoint.
+	EC2Endpoint string
+	// EC2FIPS uses FIPS endpoints for AWS API calls.
+	EC2FIPS bool
+	// Hooks run commands around each bind, in one-shot and watch mode alike.
+	// Nil when no hook is configured.
+	Hooks *Hooks
+	// Webhook is notified whenever the address moves. Nil disables
+	// notifications.
+	Webhook *Webhook
+	// DNS, when set, publishes the bound address in Route 53. Nil disables
+	// the DNS step.
+	DNS *DNSOptions
+	// Audit, when set, appends a record of every bind and unbind to a file.
+	Audit *AuditOptions
+	// Output selects whether results and errors are also printed as JSON.
+	Output OutputFormat
+}
+
+// targetOptions carries the flags that influence target resolution.
+type targetOptions struct {
+	ipv6StateFile    string
+	ordinalAddresses string
+	ordinalSortTag   string
+	instanceTag      string
 }
 
 // ParseConfig resolves the target IP from CLI arguments and environment variables.
+// Flags are parsed first; the remaining positional argument is the target.
+// Each flag sets the Config field of the same purpose.
 //
-// If the first argument is "POD_NAME", it reads the POD_NAME environment variable,
+// If the target is "POD_NAME", it reads the POD_NAME environment variable,
 // replaces hyphens with underscores, and uses the resulting key to look up the
 // actual IP from the environment. This is useful when running as a Kubernetes
 // init container.
 //
+// Targets that can only be resolved at runtime, such as PodAnnotationTarget,
+// InstanceTagTarget, SSM parameters, and tagged address pools, set TargetRef;
+// the caller resolves them and passes the value to SetTarget.
+//
 // getenv is an injectable function for reading environment variables (typically os.Getenv).
 func ParseConfig(args []string, getenv func(string) string) (*Config, error) {
+	fs := flag.NewFlagSet("aws-eip-binding", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ipv6StateFile := fs.String("ipv6-state-file", "", "file that records the IPv6 address picked in "+AutoIPv6+" mode for later rebinds")
+	configureOS := fs.Bool("configure-os", false, "add the bound IPv6 address to the local interface via netlink (Linux only)")
+	primaryIPv6 := fs.String("ipv6-primary", "", "primary IPv6 handling for IPv6 targets: \"verify\" or \"request\"")
+	ordinalAddresses := fs.String("ordinal-addresses", "", "address list for "+PodOrdinalTarget+": env:NAME, file:PATH, tag:KEY=VALUE, or a comma-separated list")
+	watch := fs.Bool("watch", false, "keep running and rebind when an @PATH target file changes")
+	watchInterval := fs.Duration("watch-interval", DefaultWatchInterval, "how often -watch re-reads the target file")
+	ordinalSortTag := fs.String("ordinal-sort-tag", "", "tag whose value orders the tag:KEY=VALUE address pool")
+	instance := fs.String("instance", "", "bind to this instance instead of the current one: an instance ID or tag:KEY=VALUE")
+	onInterruption := fs.String("on-interruption", "", "on spot interruption, rebalance recommendation, or SIGTERM: \"unbind\" or \"handoff:INSTANCE\" (instance ID or tag:KEY=VALUE)")
+	interruptionInterval := fs.Duration("interruption-interval", DefaultInterruptionInterval, "how often -on-interruption polls instance metadata")
+	awsFlags := RegisterAWSFlags(fs)
+	webhookURL := fs.String("webhook-url", "", "URL notified with a JSON POST whenever the address moves")
+	webhookSecret := fs.String("webhook-secret", getenv("EIP_BINDING_WEBHOOK_SECRET"), "HMAC-SHA256 key signing webhook requests (default $EIP_BINDING_WEBHOOK_SECRET)")
+	webhookTimeout := fs.Duration("webhook-timeout", DefaultWebhookTimeout, "timeout for each webhook attempt")
+	webhookMaxAttempts := fs.Int("webhook-max-attempts", DefaultWebhookMaxAttempts, "webhook attempts, including the first")
+	dnsName := fs.String("dns-name", "", "DNS name whose A or AAAA record follows the bound address")
+	dnsZoneID := fs.String("dns-zone-id", "", "Route 53 hosted zone ID holding -dns-name")
+	dnsTTL := fs.Int64("dns-ttl", DefaultDNSTTL, "TTL in seconds of the -dns-name record")
+	output := fs.String("output", string(OutputText), "stdout format: \"text\" or \"json\"")
+	auditLog := fs.String("audit-log", "", "file that a JSON record of every bind and unbind is appended to")
+	auditLogMaxSize := fs.Int64("audit-log-max-size", DefaultAuditMaxBytes>>20, "size in megabytes at which -audit-log is rotated (0 disables rotation)")
+	auditLogMaxBackups := fs.Int("audit-log-max-backups", DefaultAuditMaxBackups, "rotated -audit-log files to keep")
+	var preBindHooks, postBindHooks []string
+	fs.Func("pre-bind-hook", "shell command run before each bind (repeatable)", func(value string) error {
+		preBindHooks = append(preBindHooks, value)
+		return nil
+	})
+	fs.Func("post-bind-hook", "shell command run after each successful bind (repeatable)", func(value string) error {
+		postBindHooks = append(postBindHooks, value)
+		return nil
+	})
+	hookTimeout := fs.Duration("hook-timeout", DefaultHookTimeout, "timeout for each hook command")
+	preBindHookFailure := fs.String("pre-bind-hook-failure", string(HookFailureAbort), "when a pre-bind hook fails: \"abort\" the bind or \"continue\"")
+	instanceTag := fs.String("instance-tag", "", "instance tag read for "+InstanceTagTarget+" (default \""+DefaultInstanceTag+"\")")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, usageError(fs)
+		}
+		return nil, fmt.Errorf("%w\n%s", err, usageError(fs))
+	}
+	args = fs.Args()
 	if len(args) < 1 {
-		return nil, fmt.Errorf("usage: aws-eip-binding <EIP>")
+		return nil, usageError(fs)
+	}
+
+	cfg, err := resolveTarget(args[0], targetOptions{
+		ipv6StateFile:    *ipv6StateFile,
+		ordinalAddresses: *ordinalAddresses,
+		ordinalSortTag:   *ordinalSortTag,
+		instanceTag:      *instanceTag,
+	}, getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.ConfigureOS = *configureOS
+	if *instance != "" {
+		cfg.Instance, err = ParseInstanceSelector(*instance)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.ConfigureOS {
+			return nil, fmt.Errorf("-configure-os cannot be used with -instance")
+		}
+	}
+	if *onInterruption != "" {
+		cfg.OnInterruption, err = ParseInterruptionAction(*onInterruption)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Instance != nil {
+			return nil, fmt.Errorf("-on-interruption cannot be used with -instance")
+		}
+	}
+	cfg.InterruptionInterval = *interruptionInterval
+	if cfg.InterruptionInterval <= 0 {
+		return nil, fmt.Errorf("-interruption-interval must be positive")
+	}
+	if err := awsFlags.Apply(cfg); err != nil {
+		return nil, err
+	}
+	cfg.Watch = *watch
+	cfg.WatchInterval = *watchInterval
+	if cfg.Watch && cfg.TargetFile == "" {
+		return nil, fmt.Errorf("-watch requires an %sPATH target", TargetFilePrefix)
+	}
+	if cfg.WatchInterval <= 0 {
+		return nil, fmt.Errorf("-watch-interval must be positive")
+	}
+	cfg.Webhook, err = ParseWebhook(*webhookURL, *webhookSecret, *webhookTimeout, *webhookMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DNS, err = parseDNS(*dnsName, *dnsZoneID, *dnsTTL)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Output, err = ParseOutputFormat(*output)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Audit, err = ParseAudit(*auditLog, *auditLogMaxSize, *auditLogMaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Hooks, err = parseHooks(preBindHooks, postBindHooks, *hookTimeout, *preBindHookFailure)
+	if err != nil {
+		return nil, err
+	}
+	cfg.PrimaryIPv6, err = ParsePrimaryIPv6Mode(*primaryIPv6)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TargetRef == "" {
+		if err := cfg.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// ParseWebhook validates the -webhook-* flag values. It returns nil when no
+// URL is set.
+func ParseWebhook(webhookURL, secret string, timeout time.Duration, maxAttempts int) (*Webhook, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("-webhook-timeout must be positive")
+	}
+	if maxAttempts <= 0 {
+		return nil, fmt.Errorf("-webhook-max-attempts must be positive")
+	}
+	if webhookURL == "" {
+		return nil, nil
+	}
+	if u, err := url.Parse(webhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q (want an http or https URL)", webhookURL)
+	}
+	return &Webhook{URL: webhookURL, Secret: secret, Timeout: timeout, MaxAttempts: maxAttempts}, nil
+}
+
+// parseDNS validates the DNS flags. It returns nil when no name is set.
+func parseDNS(name, zoneID string, ttl int64) (*DNSOptions, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("-dns-ttl must be positive")
+	}
+	if name == "" && zoneID == "" {
+		return nil, nil
+	}
+	if name == "" || zoneID == "" {
+		return nil, fmt.Errorf("-dns-name and -dns-zone-id must be set together")
+	}
+	return &DNSOptions{Name: name, HostedZoneID: zoneID, TTL: ttl}, nil
+}
+
+// ParseAudit validates the -audit-log* flag values. It returns nil when no
+// file is set.
+func ParseAudit(path string, maxSizeMB int64, maxBackups int) (*AuditOptions, error) {
+	if maxSizeMB < 0 {
+		return nil, fmt.Errorf("-audit-log-max-size must not be negative")
+	}
+	if maxBackups < 1 {
+		return nil, fmt.Errorf("-audit-log-max-backups must be at least 1")
 	}
+	if path == "" {
+		return nil, nil
+	}
+	return &AuditOptions{Path: path, MaxBytes: maxSizeMB << 20, MaxBackups: maxBackups}, nil
+}
 
-	targetIP := args[0]
+// parseHooks validates the hook flags. It returns nil when no hook is set.
+func parseHooks(preBind, postBind []string, timeout time.Duration, preBindFailure string) (*Hooks, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("-hook-timeout must be positive")
+	}
+	policy, err := ParseHookFailurePolicy(preBindFailure)
+	if err != nil {
+		return nil, err
+	}
+	for _, command := range slices.Concat(preBind, postBind) {
+		if strings.TrimSpace(command) == "" {
+			return nil, fmt.Errorf("hook commands must not be empty")
+		}
+	}
+	if len(preBind) == 0 && len(postBind) == 0 {
+		return nil, nil
+	}
+	return &Hooks{PreBind: preBind, PostBind: postBind, Timeout: timeout, PreBindFailure: policy}, nil
+}
+
+// SetTarget normalizes a target resolved at runtime for cfg.TargetRef and
+// stores it in TargetIP and Family.
+func (c *Config) SetTarget(value string) error {
+	parsed, err := parseTarget(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.TargetRef, err)
+	}
+	c.TargetIP = parsed.TargetIP
+	c.Family = parsed.Family
+	return c.validate()
+}
+
+// AWSFlags are the flags that configure the AWS clients rather than the
+// bind: -role-*, -imds-*, -network-stack, -ec2-endpoint, and -fips. Commands
+// that build their own binder register them with RegisterAWSFlags.
+type AWSFlags struct {
+	roleARN          *string
+	addressRoleARN   *string
+	externalID       *string
+	sessionName      *string
+	imdsEndpoint     *string
+	imdsEndpointMode *string
+	imdsDisableV1    *bool
+	imdsTimeout      *time.Duration
+	imdsMaxAttempts  *int
+	networkStack     *string
+	ec2Endpoint      *string
+	ec2FIPS          *bool
+}
+
+// RegisterAWSFlags defines the AWS client flags on fs.
+func RegisterAWSFlags(fs *flag.FlagSet) *AWSFlags {
+	return &AWSFlags{
+		roleARN:          fs.String("role-arn", "", "IAM role to assume for EC2 calls"),
+		addressRoleARN:   fs.String("address-role-arn", "", "IAM role to assume for Elastic IP calls (DescribeAddresses, AssociateAddress, DisassociateAddress)"),
+		externalID:       fs.String("role-external-id", "", "external ID passed when assuming -role-arn and -address-role-arn"),
+		sessionName:      fs.String("role-session-name", "", "role session name (default \"aws-eip-binding-<instance ID>\")"),
+		imdsEndpoint:     fs.String("imds-endpoint", "", "instance metadata endpoint URL (overrides -imds-endpoint-mode)"),
+		imdsEndpointMode: fs.String("imds-endpoint-mode", "", "instance metadata endpoint: \"ipv4\" or \"ipv6\" (default from the target family)"),
+		imdsDisableV1:    fs.Bool("imds-disable-v1", false, "require IMDSv2 instead of falling back to IMDSv1"),
+		imdsTimeout:      fs.Duration("imds-timeout", 0, "timeout for each instance metadata request (default 5s)"),
+		imdsMaxAttempts:  fs.Int("imds-max-attempts", 0, "attempts per instance metadata request (default 3)"),
+		networkStack:     fs.String("network-stack", "", "IMDS and EC2 endpoint stack: auto, ipv4, ipv6, or dual-stack (default from the target family)"),
+		ec2Endpoint:      fs.String("ec2-endpoint", "", "EC2 API endpoint URL, such as a VPC interface endpoint"),
+		ec2FIPS:          fs.Bool("fips", false, "use FIPS endpoints for AWS API calls"),
+	}
+}
+
+// Apply validates the parsed flags and stores them in cfg.
+func (f *AWSFlags) Apply(cfg *Config) error {
+	var err error
+	cfg.Role, cfg.AddressRole, err = parseRoles(*f.roleARN, *f.addressRoleARN, *f.externalID, *f.sessionName)
+	if err != nil {
+		return err
+	}
+	cfg.IMDS, err = parseIMDSOptions(*f.imdsEndpoint, *f.imdsEndpointMode, *f.imdsDisableV1, *f.imdsTimeout, *f.imdsMaxAttempts)
+	if err != nil {
+		return err
+	}
+	cfg.NetworkStack, err = ParseNetworkStack(*f.networkStack)
+	if err != nil {
+		return err
+	}
+	if *f.ec2Endpoint != "" {
+		if u, err := url.Parse(*f.ec2Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid -ec2-endpoint %q (want an http or https URL)", *f.ec2Endpoint)
+		}
+	}
+	cfg.EC2Endpoint = *f.ec2Endpoint
+	cfg.EC2FIPS = *f.ec2FIPS
+	return nil
+}
+
+// parseRoles builds the roles to assume from the -role-* flags. The external
+// ID and session name apply to both roles.
+func parseRoles(roleARN, addressRoleARN, externalID, sessionName string) (role, addressRole *AssumeRole, err error) {
+	if roleARN == "" && addressRoleARN == "" {
+		if externalID != "" || sessionName != "" {
+			return nil, nil, fmt.Errorf("-role-external-id and -role-session-name require -role-arn or -address-role-arn")
+		}
+		return nil, nil, nil
+	}
+	if roleARN != "" {
+		if err := parseRoleARN("-role-arn", roleARN); err != nil {
+			return nil, nil, err
+		}
+		role = &AssumeRole{RoleARN: roleARN, ExternalID: externalID, SessionName: sessionName}
+	}
+	if addressRoleARN != "" {
+		if err := parseRoleARN("-address-role-arn", addressRoleARN); err != nil {
+			return nil, nil, err
+		}
+		addressRole = &AssumeRole{RoleARN: addressRoleARN, ExternalID: externalID, SessionName: sessionName}
+	}
+	return role, addressRole, nil
+}
+
+func (c *Config) validate() error {
+	if c.PrimaryIPv6 != PrimaryIPv6Ignore && (c.Family != IPFamilyIPv6 || strings.Contains(c.TargetIP, "/")) {
+		return fmt.Errorf("-ipv6-primary requires an IPv6 address target")
+	}
+	if c.DNS != nil && strings.Contains(c.TargetIP, "/") {
+		return fmt.Errorf("-dns-name cannot be used with IPv6 prefix targets")
+	}
+	return nil
+}
+
+func resolveTarget(targetIP string, opts targetOptions, getenv func(string) string) (*Config, error) {
+	if targetIP != PodOrdinalTarget && (opts.ordinalAddresses != "" || opts.ordinalSortTag != "") {
+		return nil, fmt.Errorf("-ordinal-addresses and -ordinal-sort-tag require the %s target", PodOrdinalTarget)
+	}
+	if targetIP != InstanceTagTarget && opts.instanceTag != "" {
+		return nil, fmt.Errorf("-instance-tag requires the %s target", InstanceTagTarget)
+	}
+	if targetIP == PodOrdinalTarget {
+		return resolvePodOrdinal(opts, getenv)
+	}
 
 	if targetIP == "POD_NAME" {
 		podName := getenv("POD_NAME")
@@ -42,6 +439,108 @@ func ParseConfig(args []string, getenv func(string) string) (*Config, error) {
 		}
 	}
 
+	if targetIP == AutoIPv6 {
+		cfg := &Config{TargetIP: AutoIPv6, Family: IPFamilyIPv6, IPv6StateFile: opts.ipv6StateFile}
+		if cfg.IPv6StateFile == "" {
+			return cfg, nil
+		}
+		saved, err := ReadIPv6State(cfg.IPv6StateFile)
+		if err != nil {
+			return nil, err
+		}
+		if saved.IsValid() {
+			cfg.TargetIP = saved.String()
+		}
+		return cfg, nil
+	}
+	if opts.ipv6StateFile != "" {
+		return nil, fmt.Errorf("-ipv6-state-file requires the %s target", AutoIPv6)
+	}
+
+	if targetIP == InstanceTagTarget {
+		key := opts.instanceTag
+		if key == "" {
+			key = DefaultInstanceTag
+		}
+		return &Config{TargetRef: InstanceTagTarget, InstanceTag: key}, nil
+	}
+	if targetIP == PodAnnotationTarget || IsRemoteTarget(targetIP) {
+		return &Config{TargetRef: targetIP}, nil
+	}
+
+	if path, ok := strings.CutPrefix(targetIP, TargetFilePrefix); ok {
+		target, err := ReadTargetFile(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := parseTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TargetFile = path
+		return cfg, nil
+	}
+
+	return parseTarget(targetIP)
+}
+
+func resolvePodOrdinal(opts targetOptions, getenv func(string) string) (*Config, error) {
+	if opts.ipv6StateFile != "" {
+		return nil, fmt.Errorf("-ipv6-state-file requires the %s target", AutoIPv6)
+	}
+	if opts.ordinalAddresses == "" {
+		return nil, fmt.Errorf("%s requires -ordinal-addresses", PodOrdinalTarget)
+	}
+	podName := getenv("POD_NAME")
+	if podName == "" {
+		return nil, fmt.Errorf("environment variable POD_NAME is empty")
+	}
+	ordinal, err := ParsePodOrdinal(podName)
+	if err != nil {
+		return nil, err
+	}
+
+	if selector, ok := strings.CutPrefix(opts.ordinalAddresses, "tag:"); ok {
+		pool, err := parseAddressPool(selector, opts.ordinalSortTag, ordinal)
+		if err != nil {
+			return nil, err
+		}
+		return &Config{TargetRef: PodOrdinalTarget, AddressPool: pool}, nil
+	}
+	if opts.ordinalSortTag != "" {
+		return nil, fmt.Errorf("-ordinal-sort-tag only applies to tag:KEY=VALUE address pools")
+	}
+
+	addresses, err := readAddressList(opts.ordinalAddresses, getenv)
+	if err != nil {
+		return nil, err
+	}
+	if ordinal >= len(addresses) {
+		return nil, fmt.Errorf("ordinal %d (from POD_NAME=%s) is out of range for %d addresses", ordinal, podName, len(addresses))
+	}
+	return parseTarget(addresses[ordinal])
+}
+
+// NormalizeTarget returns the canonical form of a literal IPv4 address, IPv6
+// address, or IPv6 prefix, as Bind reports it in BindResult.TargetIP.
+func NormalizeTarget(value string) (string, error) {
+	cfg, err := parseTarget(value)
+	if err != nil {
+		return "", err
+	}
+	return cfg.TargetIP, nil
+}
+
+// parseTarget normalizes a literal IPv4 address, IPv6 address, or IPv6 prefix.
+func parseTarget(targetIP string) (*Config, error) {
+	if strings.Contains(targetIP, "/") {
+		prefix, err := parseTargetPrefix(targetIP)
+		if err != nil {
+			return nil, err
+		}
+		return &Config{TargetIP: prefix.String(), Family: IPFamilyIPv6}, nil
+	}
+
 	ip, err := netip.ParseAddr(targetIP)
 	if err != nil {
 		return nil, fmt.Errorf("invalid IP address: %s", targetIP)
@@ -58,7 +557,17 @@ func ParseConfig(args []string, getenv func(string) string) (*Config, error) {
 // ParseConfigFromOS is a convenience wrapper that calls ParseConfig with os.Args and os.Getenv.
 func ParseConfigFromOS() (*Config, error) {
 	if len(os.Args) < 2 {
-		return nil, fmt.Errorf("usage: aws-eip-binding <EIP>")
+		return nil, errors.New(usageLine)
 	}
 	return ParseConfig(os.Args[1:], os.Getenv)
 }
+
+func usageError(fs *flag.FlagSet) error {
+	var b strings.Builder
+	b.WriteString(usageLine)
+	b.WriteString("\n\nflags:\n")
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+	return errors.New(strings.TrimRight(b.String(), "\n"))
+}
@@ -2,7 +2,12 @@ package eip
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	ec2imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 )
 
 func TestParseConfig(t *testing.T) {
@@ -37,6 +42,401 @@ func TestParseConfig(t *testing.T) {
 			args: []string{"::ffff:54.162.153.80"},
 			want: Config{TargetIP: "54.162.153.80", Family: IPFamilyIPv4},
 		},
+		{
+			name: "IPv6 prefix normalized",
+			args: []string{"2001:0db8:0000:0000:0001::/80"},
+			want: Config{TargetIP: "2001:db8:0:0:1::/80", Family: IPFamilyIPv6},
+		},
+		{
+			name:    "IPv6 prefix with wrong length",
+			args:    []string{"2001:db8::/64"},
+			wantErr: true,
+		},
+		{
+			name:    "IPv6 prefix with host bits set",
+			args:    []string{"2001:db8::1:0:0:1/80"},
+			wantErr: true,
+		},
+		{
+			name:    "IPv4 prefix",
+			args:    []string{"10.0.0.0/28"},
+			wantErr: true,
+		},
+		{
+			name: "AUTO_IPV6 without state file",
+			args: []string{"AUTO_IPV6"},
+			want: Config{TargetIP: AutoIPv6, Family: IPFamilyIPv6},
+		},
+		{
+			name:    "state file requires AUTO_IPV6",
+			args:    []string{"-ipv6-state-file", "/tmp/eip", "2001:db8::1"},
+			wantErr: true,
+		},
+		{
+			name: "primary IPv6 verify",
+			args: []string{"-ipv6-primary", "verify", "2001:db8::1"},
+			want: Config{TargetIP: "2001:db8::1", Family: IPFamilyIPv6, PrimaryIPv6: PrimaryIPv6Verify},
+		},
+		{
+			name: "primary IPv6 request with AUTO_IPV6",
+			args: []string{"-ipv6-primary", "request", "AUTO_IPV6"},
+			want: Config{TargetIP: AutoIPv6, Family: IPFamilyIPv6, PrimaryIPv6: PrimaryIPv6Request},
+		},
+		{
+			name:    "primary IPv6 invalid mode",
+			args:    []string{"-ipv6-primary", "always", "2001:db8::1"},
+			wantErr: true,
+		},
+		{
+			name:    "primary IPv6 rejects IPv4 target",
+			args:    []string{"-ipv6-primary", "verify", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "primary IPv6 rejects prefix target",
+			args:    []string{"-ipv6-primary", "verify", "2001:db8:0:0:1::/80"},
+			wantErr: true,
+		},
+		{
+			name: "configure OS",
+			args: []string{"-configure-os", "2001:db8::1"},
+			want: Config{TargetIP: "2001:db8::1", Family: IPFamilyIPv6, ConfigureOS: true},
+		},
+		{
+			name: "POD_ANNOTATION defers resolution",
+			args: []string{"POD_ANNOTATION"},
+			want: Config{TargetRef: PodAnnotationTarget},
+		},
+		{
+			name: "off-instance by ID",
+			args: []string{"-instance", "i-0123456789abcdef0", "54.162.153.80"},
+			want: Config{TargetIP: "54.162.153.80", Family: IPFamilyIPv4, Instance: &InstanceSelector{InstanceID: "i-0123456789abcdef0"}},
+		},
+		{
+			name: "off-instance by tag",
+			args: []string{"-instance", "tag:Name=web-primary", "INSTANCE_TAG"},
+			want: Config{
+				TargetRef:   InstanceTagTarget,
+				InstanceTag: DefaultInstanceTag,
+				Instance:    &InstanceSelector{TagKey: "Name", TagValue: "web-primary"},
+			},
+		},
+		{
+			name:    "off-instance rejects configure OS",
+			args:    []string{"-instance", "i-0123456789abcdef0", "-configure-os", "2001:db8::1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid instance selector",
+			args:    []string{"-instance", "web", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "unbind on interruption",
+			args: []string{"-on-interruption", "unbind", "54.162.153.80"},
+			want: Config{
+				TargetIP:             "54.162.153.80",
+				Family:               IPFamilyIPv4,
+				OnInterruption:       &InterruptionAction{},
+				InterruptionInterval: DefaultInterruptionInterval,
+			},
+		},
+		{
+			name: "hand off on interruption",
+			args: []string{"-on-interruption", "handoff:tag:Name=web-standby", "-interruption-interval", "2s", "2001:db8::1"},
+			want: Config{
+				TargetIP:             "2001:db8::1",
+				Family:               IPFamilyIPv6,
+				OnInterruption:       &InterruptionAction{Standby: &InstanceSelector{TagKey: "Name", TagValue: "web-standby"}},
+				InterruptionInterval: 2 * time.Second,
+			},
+		},
+		{
+			name:    "invalid interruption action",
+			args:    []string{"-on-interruption", "release", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "interruption action requires current instance",
+			args:    []string{"-on-interruption", "unbind", "-instance", "i-0123456789abcdef0", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "assume role",
+			args: []string{"-role-arn", "arn:aws:iam::111111111111:role/eip-binding", "-role-external-id", "workload", "54.162.153.80"},
+			want: Config{
+				TargetIP: "54.162.153.80",
+				Family:   IPFamilyIPv4,
+				Role:     &AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/eip-binding", ExternalID: "workload"},
+			},
+		},
+		{
+			name: "separate address role",
+			args: []string{"-address-role-arn", "arn:aws:iam::222222222222:role/eip-addresses", "-role-session-name", "failover", "54.162.153.80"},
+			want: Config{
+				TargetIP:    "54.162.153.80",
+				Family:      IPFamilyIPv4,
+				AddressRole: &AssumeRole{RoleARN: "arn:aws:iam::222222222222:role/eip-addresses", SessionName: "failover"},
+			},
+		},
+		{
+			name:    "invalid role ARN",
+			args:    []string{"-role-arn", "arn:aws:iam::111111111111:user/eip", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "external ID requires a role",
+			args:    []string{"-role-external-id", "workload", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "IMDS options",
+			args: []string{
+				"-imds-endpoint", "http://169.254.169.254",
+				"-imds-endpoint-mode", "ipv6",
+				"-imds-disable-v1",
+				"-imds-timeout", "2s",
+				"-imds-max-attempts", "5",
+				"54.162.153.80",
+			},
+			want: Config{
+				TargetIP: "54.162.153.80",
+				Family:   IPFamilyIPv4,
+				IMDS: IMDSOptions{
+					Endpoint:     "http://169.254.169.254",
+					EndpointMode: ec2imds.EndpointModeStateIPv6,
+					DisableV1:    true,
+					Timeout:      2 * time.Second,
+					MaxAttempts:  5,
+				},
+			},
+		},
+		{
+			name:    "invalid IMDS endpoint",
+			args:    []string{"-imds-endpoint", "169.254.169.254", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid IMDS endpoint mode",
+			args:    []string{"-imds-endpoint-mode", "dual", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "negative IMDS attempts",
+			args:    []string{"-imds-max-attempts", "-1", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "network stack and EC2 endpoint",
+			args: []string{"-network-stack", "dual-stack", "-ec2-endpoint", "https://vpce-0123.ec2.us-east-1.vpce.amazonaws.com", "-fips", "54.162.153.80"},
+			want: Config{
+				TargetIP:     "54.162.153.80",
+				Family:       IPFamilyIPv4,
+				NetworkStack: NetworkStackDualStack,
+				EC2Endpoint:  "https://vpce-0123.ec2.us-east-1.vpce.amazonaws.com",
+				EC2FIPS:      true,
+			},
+		},
+		{
+			name: "bind hooks",
+			args: []string{"-pre-bind-hook", "systemctl stop nginx", "-post-bind-hook", "systemctl start nginx", "-post-bind-hook", "notify.sh",
+				"-hook-timeout", "10s", "-pre-bind-hook-failure", "continue", "54.162.153.80"},
+			want: Config{
+				TargetIP: "54.162.153.80",
+				Family:   IPFamilyIPv4,
+				Hooks: &Hooks{
+					PreBind:        []string{"systemctl stop nginx"},
+					PostBind:       []string{"systemctl start nginx", "notify.sh"},
+					Timeout:        10 * time.Second,
+					PreBindFailure: HookFailureContinue,
+				},
+			},
+		},
+		{
+			name: "webhook with secret from environment",
+			args: []string{"-webhook-url", "https://hooks.example.com/eip", "-webhook-max-attempts", "5", "54.162.153.80"},
+			env:  map[string]string{"EIP_BINDING_WEBHOOK_SECRET": "s3cret"},
+			want: Config{
+				TargetIP: "54.162.153.80",
+				Family:   IPFamilyIPv4,
+				Webhook: &Webhook{
+					URL:         "https://hooks.example.com/eip",
+					Secret:      "s3cret",
+					Timeout:     DefaultWebhookTimeout,
+					MaxAttempts: 5,
+				},
+			},
+		},
+		{
+			name: "DNS record",
+			args: []string{"-dns-name", "api.example.com", "-dns-zone-id", "Z0123456789ABC", "2001:db8::1"},
+			want: Config{
+				TargetIP: "2001:db8::1",
+				Family:   IPFamilyIPv6,
+				DNS:      &DNSOptions{Name: "api.example.com", HostedZoneID: "Z0123456789ABC", TTL: DefaultDNSTTL},
+			},
+		},
+		{
+			name:    "DNS name without zone",
+			args:    []string{"-dns-name", "api.example.com", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "DNS record for IPv6 prefix",
+			args:    []string{"-dns-name", "api.example.com", "-dns-zone-id", "Z0123456789ABC", "2001:db8:1:2:3::/80"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid DNS TTL",
+			args:    []string{"-dns-name", "api.example.com", "-dns-zone-id", "Z0123456789ABC", "-dns-ttl", "0", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "audit log",
+			args: []string{"-audit-log", "/var/log/eip-binding/audit.jsonl", "-audit-log-max-size", "1", "54.162.153.80"},
+			want: Config{
+				TargetIP: "54.162.153.80",
+				Family:   IPFamilyIPv4,
+				Audit:    &AuditOptions{Path: "/var/log/eip-binding/audit.jsonl", MaxBytes: 1 << 20, MaxBackups: DefaultAuditMaxBackups},
+			},
+		},
+		{
+			name:    "negative audit log backups",
+			args:    []string{"-audit-log", "audit.jsonl", "-audit-log-max-backups", "-1", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "zero audit log backups",
+			args:    []string{"-audit-log", "audit.jsonl", "-audit-log-max-backups", "0", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "JSON output",
+			args: []string{"-output", "json", "54.162.153.80"},
+			want: Config{TargetIP: "54.162.153.80", Family: IPFamilyIPv4, Output: OutputJSON},
+		},
+		{
+			name:    "invalid output format",
+			args:    []string{"-output", "yaml", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid webhook URL",
+			args:    []string{"-webhook-url", "hooks.example.com", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pre-bind hook failure policy",
+			args:    []string{"-pre-bind-hook", "true", "-pre-bind-hook-failure", "ignore", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "empty hook command",
+			args:    []string{"-post-bind-hook", " ", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid network stack",
+			args:    []string{"-network-stack", "ipv5", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid EC2 endpoint",
+			args:    []string{"-ec2-endpoint", "vpce-0123", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "INSTANCE_TAG defers resolution with default tag",
+			args: []string{"INSTANCE_TAG"},
+			want: Config{TargetRef: InstanceTagTarget, InstanceTag: DefaultInstanceTag},
+		},
+		{
+			name: "INSTANCE_TAG with custom tag",
+			args: []string{"-instance-tag", "eip-binding:address", "INSTANCE_TAG"},
+			want: Config{TargetRef: InstanceTagTarget, InstanceTag: "eip-binding:address"},
+		},
+		{
+			name:    "instance tag requires INSTANCE_TAG target",
+			args:    []string{"-instance-tag", "eip", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name: "SSM parameter defers resolution",
+			args: []string{"ssm:/prod/web/eip"},
+			want: Config{TargetRef: "ssm:/prod/web/eip"},
+		},
+		{
+			name: "Secrets Manager key from POD_NAME defers resolution",
+			args: []string{"POD_NAME"},
+			env: map[string]string{
+				"POD_NAME": "web-0",
+				"web_0":    "secretsmanager:prod/eips#web-0",
+			},
+			want: Config{TargetRef: "secretsmanager:prod/eips#web-0"},
+		},
+		{
+			name: "POD_ORDINAL from literal list",
+			args: []string{"-ordinal-addresses", "54.162.153.80, 54.162.153.81", "POD_ORDINAL"},
+			env:  map[string]string{"POD_NAME": "web-1"},
+			want: Config{TargetIP: "54.162.153.81", Family: IPFamilyIPv4},
+		},
+		{
+			name: "POD_ORDINAL from environment list",
+			args: []string{"-ordinal-addresses", "env:WEB_ADDRESSES", "POD_ORDINAL"},
+			env: map[string]string{
+				"POD_NAME":      "web-0",
+				"WEB_ADDRESSES": "2001:db8::a,2001:db8::b",
+			},
+			want: Config{TargetIP: "2001:db8::a", Family: IPFamilyIPv6},
+		},
+		{
+			name: "POD_ORDINAL from tag pool is deferred",
+			args: []string{"-ordinal-addresses", "tag:pool=web", "-ordinal-sort-tag", "slot", "POD_ORDINAL"},
+			env:  map[string]string{"POD_NAME": "web-2"},
+			want: Config{
+				TargetRef:   PodOrdinalTarget,
+				AddressPool: &AddressPool{TagKey: "pool", TagValue: "web", SortTag: "slot", Index: 2},
+			},
+		},
+		{
+			name:    "POD_ORDINAL tag pool requires sort tag",
+			args:    []string{"-ordinal-addresses", "tag:pool=web", "POD_ORDINAL"},
+			env:     map[string]string{"POD_NAME": "web-2"},
+			wantErr: true,
+		},
+		{
+			name:    "POD_ORDINAL out of range",
+			args:    []string{"-ordinal-addresses", "54.162.153.80", "POD_ORDINAL"},
+			env:     map[string]string{"POD_NAME": "web-1"},
+			wantErr: true,
+		},
+		{
+			name:    "POD_ORDINAL without ordinal suffix",
+			args:    []string{"-ordinal-addresses", "54.162.153.80", "POD_ORDINAL"},
+			env:     map[string]string{"POD_NAME": "web"},
+			wantErr: true,
+		},
+		{
+			name:    "POD_ORDINAL requires address list",
+			args:    []string{"POD_ORDINAL"},
+			env:     map[string]string{"POD_NAME": "web-0"},
+			wantErr: true,
+		},
+		{
+			name:    "ordinal flags require POD_ORDINAL",
+			args:    []string{"-ordinal-addresses", "54.162.153.80", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-bogus", "54.162.153.80"},
+			wantErr: true,
+		},
+		{
+			name:    "flags without target",
+			args:    []string{"-ipv6-state-file", "/tmp/eip"},
+			wantErr: true,
+		},
 		{
 			name: "POD_NAME mode resolves IPv4",
 			args: []string{"POD_NAME"},
@@ -96,6 +496,121 @@ func TestParseConfig(t *testing.T) {
 	}
 }
 
+func TestParseConfigAutoIPv6StateFile(t *testing.T) {
+	dir := t.TempDir()
+
+	missing := filepath.Join(dir, "missing")
+	cfg, err := ParseConfig([]string{"-ipv6-state-file", missing, "AUTO_IPV6"}, getenvFromMap(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertConfig(t, cfg, Config{TargetIP: AutoIPv6, Family: IPFamilyIPv6, IPv6StateFile: missing})
+
+	saved := filepath.Join(dir, "saved")
+	if err := WriteIPv6State(saved, "2001:db8::abcd"); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+	cfg, err = ParseConfig([]string{"-ipv6-state-file", saved, "AUTO_IPV6"}, getenvFromMap(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertConfig(t, cfg, Config{TargetIP: "2001:db8::abcd", Family: IPFamilyIPv6, IPv6StateFile: saved})
+
+	corrupt := filepath.Join(dir, "corrupt")
+	if err := os.WriteFile(corrupt, []byte("54.162.153.80\n"), 0o600); err != nil {
+		t.Fatalf("write corrupt state: %v", err)
+	}
+	if _, err := ParseConfig([]string{"-ipv6-state-file", corrupt, "AUTO_IPV6"}, getenvFromMap(nil)); err == nil {
+		t.Fatal("expected error for IPv4 in state file, got nil")
+	}
+}
+
+func TestParseConfigPodOrdinalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addresses")
+	list := "# web StatefulSet\n54.162.153.80\n\n54.162.153.81 # web-1\n"
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatalf("write address list: %v", err)
+	}
+
+	cfg, err := ParseConfig([]string{"-ordinal-addresses", "file:" + path, "POD_ORDINAL"}, getenvFromMap(map[string]string{"POD_NAME": "web-1"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertConfig(t, cfg, Config{TargetIP: "54.162.153.81", Family: IPFamilyIPv4})
+}
+
+func TestParseConfigTargetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eip")
+	if err := os.WriteFile(path, []byte("2001:db8::5\n"), 0o600); err != nil {
+		t.Fatalf("write target file: %v", err)
+	}
+
+	cfg, err := ParseConfig([]string{"-watch", "-watch-interval", "30s", "@" + path}, getenvFromMap(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertConfig(t, cfg, Config{TargetIP: "2001:db8::5", Family: IPFamilyIPv6, TargetFile: path, Watch: true, WatchInterval: 30 * time.Second})
+
+	if _, err := ParseConfig([]string{"@" + filepath.Join(t.TempDir(), "missing")}, getenvFromMap(nil)); err == nil {
+		t.Fatal("expected error for missing target file, got nil")
+	}
+	if _, err := ParseConfig([]string{"-watch", "54.162.153.80"}, getenvFromMap(nil)); err == nil {
+		t.Fatal("expected error for -watch without file target, got nil")
+	}
+}
+
+func TestConfigSetTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		value   string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name:  "normalizes resolved IPv6",
+			cfg:   Config{TargetRef: PodAnnotationTarget},
+			value: " 2001:0db8::1\n",
+			want:  Config{TargetRef: PodAnnotationTarget, TargetIP: "2001:db8::1", Family: IPFamilyIPv6},
+		},
+		{
+			name:    "rejects invalid value",
+			cfg:     Config{TargetRef: PodAnnotationTarget},
+			value:   "not-an-ip",
+			wantErr: true,
+		},
+		{
+			name:    "validates flags against resolved family",
+			cfg:     Config{TargetRef: PodAnnotationTarget, PrimaryIPv6: PrimaryIPv6Verify},
+			value:   "54.162.153.80",
+			wantErr: true,
+		},
+		{
+			name:    "rejects resolved prefix with DNS record",
+			cfg:     Config{TargetRef: InstanceTagTarget, DNS: &DNSOptions{Name: "api.example.com", HostedZoneID: "Z0123456789ABC"}},
+			value:   "2001:db8:1:2:3::/80",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			err := cfg.SetTarget(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertConfig(t, &cfg, tt.want)
+		})
+	}
+}
+
 func TestParseConfigFromOS(t *testing.T) {
 	origArgs := os.Args
 	t.Cleanup(func() {
@@ -163,4 +678,74 @@ func assertConfig(t *testing.T, got *Config, want Config) {
 	if got.Family != want.Family {
 		t.Errorf("Family = %q, want %q", got.Family, want.Family)
 	}
+	if got.TargetRef != want.TargetRef {
+		t.Errorf("TargetRef = %q, want %q", got.TargetRef, want.TargetRef)
+	}
+	if got.IPv6StateFile != want.IPv6StateFile {
+		t.Errorf("IPv6StateFile = %q, want %q", got.IPv6StateFile, want.IPv6StateFile)
+	}
+	if got.PrimaryIPv6 != want.PrimaryIPv6 {
+		t.Errorf("PrimaryIPv6 = %q, want %q", got.PrimaryIPv6, want.PrimaryIPv6)
+	}
+	if got.ConfigureOS != want.ConfigureOS {
+		t.Errorf("ConfigureOS = %v, want %v", got.ConfigureOS, want.ConfigureOS)
+	}
+	if got.TargetFile != want.TargetFile {
+		t.Errorf("TargetFile = %q, want %q", got.TargetFile, want.TargetFile)
+	}
+	if got.Watch != want.Watch {
+		t.Errorf("Watch = %v, want %v", got.Watch, want.Watch)
+	}
+	if want.WatchInterval != 0 && got.WatchInterval != want.WatchInterval {
+		t.Errorf("WatchInterval = %v, want %v", got.WatchInterval, want.WatchInterval)
+	}
+	if got.InstanceTag != want.InstanceTag {
+		t.Errorf("InstanceTag = %q, want %q", got.InstanceTag, want.InstanceTag)
+	}
+	if (got.OnInterruption == nil) != (want.OnInterruption == nil) ||
+		(got.OnInterruption != nil && got.OnInterruption.String() != want.OnInterruption.String()) {
+		t.Errorf("OnInterruption = %v, want %v", got.OnInterruption, want.OnInterruption)
+	}
+	if want.InterruptionInterval != 0 && got.InterruptionInterval != want.InterruptionInterval {
+		t.Errorf("InterruptionInterval = %v, want %v", got.InterruptionInterval, want.InterruptionInterval)
+	}
+	if want.Output != "" && got.Output != want.Output {
+		t.Errorf("Output = %q, want %q", got.Output, want.Output)
+	}
+	if got.NetworkStack != want.NetworkStack {
+		t.Errorf("NetworkStack = %q, want %q", got.NetworkStack, want.NetworkStack)
+	}
+	if got.EC2Endpoint != want.EC2Endpoint || got.EC2FIPS != want.EC2FIPS {
+		t.Errorf("EC2 endpoint = %q (FIPS %t), want %q (FIPS %t)", got.EC2Endpoint, got.EC2FIPS, want.EC2Endpoint, want.EC2FIPS)
+	}
+	if !reflect.DeepEqual(got.Webhook, want.Webhook) {
+		t.Errorf("Webhook = %+v, want %+v", got.Webhook, want.Webhook)
+	}
+	if !reflect.DeepEqual(got.Audit, want.Audit) {
+		t.Errorf("Audit = %+v, want %+v", got.Audit, want.Audit)
+	}
+	if !reflect.DeepEqual(got.DNS, want.DNS) {
+		t.Errorf("DNS = %+v, want %+v", got.DNS, want.DNS)
+	}
+	if !reflect.DeepEqual(got.Hooks, want.Hooks) {
+		t.Errorf("Hooks = %+v, want %+v", got.Hooks, want.Hooks)
+	}
+	if got.IMDS != want.IMDS {
+		t.Errorf("IMDS = %+v, want %+v", got.IMDS, want.IMDS)
+	}
+	if (got.Role == nil) != (want.Role == nil) || (got.Role != nil && *got.Role != *want.Role) {
+		t.Errorf("Role = %+v, want %+v", got.Role, want.Role)
+	}
+	if (got.AddressRole == nil) != (want.AddressRole == nil) ||
+		(got.AddressRole != nil && *got.AddressRole != *want.AddressRole) {
+		t.Errorf("AddressRole = %+v, want %+v", got.AddressRole, want.AddressRole)
+	}
+	if (got.Instance == nil) != (want.Instance == nil) ||
+		(got.Instance != nil && *got.Instance != *want.Instance) {
+		t.Errorf("Instance = %+v, want %+v", got.Instance, want.Instance)
+	}
+	if (got.AddressPool == nil) != (want.AddressPool == nil) ||
+		(got.AddressPool != nil && *got.AddressPool != *want.AddressPool) {
+		t.Errorf("AddressPool = %+v, want %+v", got.AddressPool, want.AddressPool)
+	}
 }
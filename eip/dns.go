@@ -0,0 +1,88 @@
+package eip
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// DefaultDNSTTL is the TTL of published records, short enough that clients
+// follow a failover quickly.
+const DefaultDNSTTL = 60
+
+// DNSRecord is an A or AAAA record holding a bound address.
+type DNSRecord struct {
+	Name  string
+	Type  string
+	Value string
+	TTL   int64
+}
+
+// DNSProvider publishes DNS records. Route53Provider is the AWS
+// implementation.
+type DNSProvider interface {
+	// UpsertRecord creates record or replaces the values of the record set
+	// with the same name and type.
+	UpsertRecord(ctx context.Context, record DNSRecord) error
+	// DeleteRecord deletes record if the record set still holds exactly its
+	// value, leaving a record set another instance has taken over untouched.
+	DeleteRecord(ctx context.Context, record DNSRecord) error
+}
+
+// DNSOptions configures the record published after each bind.
+type DNSOptions struct {
+	// Name is the record name, e.g. "api.example.com".
+	Name string
+	// HostedZoneID is the Route 53 hosted zone holding Name.
+	HostedZoneID string
+	TTL          int64
+}
+
+// DNSUpdater keeps Name pointing at the address Binder binds.
+type DNSUpdater struct {
+	Provider DNSProvider
+	Name     string
+	// TTL of the record. Zero uses DefaultDNSTTL.
+	TTL int64
+}
+
+// record returns the A or AAAA record for result.
+func (u *DNSUpdater) record(result *BindResult) (DNSRecord, error) {
+	if result.Prefix {
+		return DNSRecord{}, fmt.Errorf("cannot publish IPv6 prefix %s in DNS record %s", result.TargetIP, u.Name)
+	}
+	record := DNSRecord{Name: u.Name, Type: "A", Value: result.TargetIP, TTL: u.TTL}
+	if result.Family == IPFamilyIPv6 {
+		record.Type = "AAAA"
+	}
+	if record.TTL <= 0 {
+		record.TTL = DefaultDNSTTL
+	}
+	return record, nil
+}
+
+// Publish points the record at result's address.
+func (u *DNSUpdater) Publish(ctx context.Context, logger *log.Logger, result *BindResult) error {
+	record, err := u.record(result)
+	if err != nil {
+		return err
+	}
+	logger.Printf("Upserting DNS %s %s %s", record.Type, record.Name, record.Value)
+	if err := u.Provider.UpsertRecord(ctx, record); err != nil {
+		return fmt.Errorf("upsert DNS %s %s: %w", record.Type, record.Name, err)
+	}
+	return nil
+}
+
+// Withdraw deletes the record for result's address.
+func (u *DNSUpdater) Withdraw(ctx context.Context, logger *log.Logger, result *BindResult) error {
+	record, err := u.record(result)
+	if err != nil {
+		return err
+	}
+	logger.Printf("Deleting DNS %s %s %s", record.Type, record.Name, record.Value)
+	if err := u.Provider.DeleteRecord(ctx, record); err != nil {
+		return fmt.Errorf("delete DNS %s %s: %w", record.Type, record.Name, err)
+	}
+	return nil
+}
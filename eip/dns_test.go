@@ -0,0 +1,182 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// fakeDNSProvider records the changes it is asked to make.
+type fakeDNSProvider struct {
+	changes []string
+	err     error
+}
+
+func (f *fakeDNSProvider) UpsertRecord(_ context.Context, record DNSRecord) error {
+	f.changes = append(f.changes, "UPSERT "+formatDNSRecord(record))
+	return f.err
+}
+
+func (f *fakeDNSProvider) DeleteRecord(_ context.Context, record DNSRecord) error {
+	f.changes = append(f.changes, "DELETE "+formatDNSRecord(record))
+	return f.err
+}
+
+func formatDNSRecord(record DNSRecord) string {
+	return fmt.Sprintf("%s %s %s %d", record.Name, record.Type, record.Value, record.TTL)
+}
+
+func TestBindPublishesDNSRecord(t *testing.T) {
+	const (
+		targetIP   = "54.162.153.80"
+		instanceID = "i-dns"
+	)
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeAddresses = func(*ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		return &ec2.DescribeAddressesOutput{
+			Addresses: []types.Address{elasticAddress(targetIP, "eipalloc-111", "")},
+		}, nil
+	}
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+	}
+	ec2Fake.associateAddress = func(*ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+		return &ec2.AssociateAddressOutput{AssociationId: new("eipassoc-111")}, nil
+	}
+
+	provider := &fakeDNSProvider{}
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID)), silentLogger())
+	binder.DNS = &DNSUpdater{Provider: provider, Name: "api.example.com"}
+	result, err := binder.Bind(context.Background(), targetIP)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requireStrings(t, provider.changes, []string{"UPSERT api.example.com A 54.162.153.80 60"}, "DNS changes")
+
+	ec2Fake.describeAddresses = func(*ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		address := elasticAddress(targetIP, "eipalloc-111", "eipassoc-111")
+		address.NetworkInterfaceId = new("eni-primary")
+		return &ec2.DescribeAddressesOutput{Addresses: []types.Address{address}}, nil
+	}
+	ec2Fake.disassociateAddress = func(*ec2.DisassociateAddressInput) (*ec2.DisassociateAddressOutput, error) {
+		return &ec2.DisassociateAddressOutput{}, nil
+	}
+	if _, err := binder.Unbind(context.Background(), result); err != nil {
+		t.Fatalf("unexpected unbind error: %v", err)
+	}
+	requireStrings(t, provider.changes, []string{
+		"UPSERT api.example.com A 54.162.153.80 60",
+		"DELETE api.example.com A 54.162.153.80 60",
+	}, "DNS changes")
+}
+
+func TestUnbindKeepsDNSRecordOfMovedAddress(t *testing.T) {
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{describeENIByID(t, primaryENI())}
+
+	provider := &fakeDNSProvider{}
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, nil), silentLogger())
+	binder.DNS = &DNSUpdater{Provider: provider, Name: "api.example.com"}
+	result := &BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8::1", NetworkInterfaceID: "eni-primary"}
+	released, err := binder.Unbind(context.Background(), result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if released {
+		t.Fatal("released = true, want false")
+	}
+	requireStrings(t, provider.changes, nil, "DNS changes")
+	ec2Fake.assertCalls([]string{"DescribeNetworkInterfaces"})
+}
+
+func TestBindDNSFailureFailsBind(t *testing.T) {
+	const targetIP = "54.162.153.80"
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeAddresses = func(*ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		address := elasticAddress(targetIP, "eipalloc-111", "eipassoc-111")
+		address.NetworkInterfaceId = new("eni-primary")
+		return &ec2.DescribeAddressesOutput{Addresses: []types.Address{address}}, nil
+	}
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+	}
+
+	provider := &fakeDNSProvider{err: errors.New("throttled")}
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata("i-dns")), silentLogger())
+	binder.DNS = &DNSUpdater{Provider: provider, Name: "api.example.com", TTL: 30}
+	_, err := binder.Bind(context.Background(), targetIP)
+	if err == nil || !strings.Contains(err.Error(), "upsert DNS A api.example.com: throttled") {
+		t.Fatalf("error = %v, want DNS upsert error", err)
+	}
+	requireStrings(t, provider.changes, []string{"UPSERT api.example.com A 54.162.153.80 30"}, "DNS changes")
+}
+
+func TestBindRejectsPrefixWithDNSBeforeMoving(t *testing.T) {
+	provider := &fakeDNSProvider{}
+	binder := NewBinder(newFakeEC2(t), newFakeIMDS(t, nil), silentLogger())
+	binder.DNS = &DNSUpdater{Provider: provider, Name: "api.example.com"}
+	_, err := binder.Bind(context.Background(), "2001:db8:1:2:3::/80")
+	if err == nil || !strings.Contains(err.Error(), "cannot publish IPv6 prefix") {
+		t.Fatalf("error = %v, want prefix rejection", err)
+	}
+	if len(provider.changes) != 0 {
+		t.Fatalf("DNS changes = %v, want none", provider.changes)
+	}
+}
+
+func TestDNSUpdaterRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  BindResult
+		want    DNSRecord
+		wantErr bool
+	}{
+		{
+			name:   "IPv4",
+			result: BindResult{Family: IPFamilyIPv4, TargetIP: "54.162.153.80"},
+			want:   DNSRecord{Name: "api.example.com", Type: "A", Value: "54.162.153.80", TTL: DefaultDNSTTL},
+		},
+		{
+			name:   "IPv6",
+			result: BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8::1"},
+			want:   DNSRecord{Name: "api.example.com", Type: "AAAA", Value: "2001:db8::1", TTL: DefaultDNSTTL},
+		},
+		{
+			name:    "IPv6 prefix",
+			result:  BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8:0:0:1::/80", Prefix: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updater := &DNSUpdater{Provider: &fakeDNSProvider{}, Name: "api.example.com"}
+			got, err := updater.record(&tt.result)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("record = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
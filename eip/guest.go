@@ -0,0 +1,94 @@
+package eip
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+)
+
+// GuestNetwork abstracts the guest OS interface operations needed to put a
+// bound address on the local interface backing an ENI.
+type GuestNetwork interface {
+	// InterfaceByMAC returns the name of the local interface with the given MAC address.
+	InterfaceByMAC(mac string) (string, error)
+	// AddAddress adds addr to the interface. Adding an address that is
+	// already present is not an error.
+	AddAddress(ifName string, addr netip.Prefix) error
+	// RemoveAddress removes addr from the interface. Removing an address that
+	// is not present is not an error.
+	RemoveAddress(ifName string, addr netip.Prefix) error
+}
+
+// GuestConfigurator adds and removes bound addresses inside the guest OS so
+// the instance can use them without ec2-net-utils or a similar agent.
+//
+// Elastic IPv4 addresses are NATed by the VPC and delegated IPv6 prefixes are
+// routed to the ENI, so only single IPv6 addresses are configured.
+type GuestConfigurator struct {
+	IMDS    MetadataClient
+	Network GuestNetwork
+	Logger  *log.Logger
+}
+
+// NewGuestConfigurator creates a GuestConfigurator with the given dependencies.
+func NewGuestConfigurator(imds MetadataClient, network GuestNetwork, logger *log.Logger) *GuestConfigurator {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &GuestConfigurator{
+		IMDS:    imds,
+		Network: network,
+		Logger:  logger,
+	}
+}
+
+// Configure adds the address described by result to the local interface that
+// backs result.NetworkInterfaceID.
+func (g *GuestConfigurator) Configure(ctx context.Context, result *BindResult) error {
+	ifName, addr, ok, err := g.resolve(ctx, result)
+	if err != nil || !ok {
+		return err
+	}
+	g.Logger.Printf("Adding %s to interface %s", addr, ifName)
+	if err := g.Network.AddAddress(ifName, addr); err != nil {
+		return fmt.Errorf("add %s to interface %s: %w", addr, ifName, err)
+	}
+	return nil
+}
+
+// Deconfigure removes the address described by result from the local
+// interface that backs result.NetworkInterfaceID.
+func (g *GuestConfigurator) Deconfigure(ctx context.Context, result *BindResult) error {
+	ifName, addr, ok, err := g.resolve(ctx, result)
+	if err != nil || !ok {
+		return err
+	}
+	g.Logger.Printf("Removing %s from interface %s", addr, ifName)
+	if err := g.Network.RemoveAddress(ifName, addr); err != nil {
+		return fmt.Errorf("remove %s from interface %s: %w", addr, ifName, err)
+	}
+	return nil
+}
+
+func (g *GuestConfigurator) resolve(ctx context.Context, result *BindResult) (string, netip.Prefix, bool, error) {
+	if result.Family != IPFamilyIPv6 || result.Prefix {
+		g.Logger.Printf("No guest OS configuration needed for %s %s", result.Family, result.TargetIP)
+		return "", netip.Prefix{}, false, nil
+	}
+
+	addr, err := netip.ParseAddr(result.TargetIP)
+	if err != nil {
+		return "", netip.Prefix{}, false, fmt.Errorf("invalid IP address: %s", result.TargetIP)
+	}
+	mac, err := macForNetworkInterface(ctx, g.IMDS, result.NetworkInterfaceID)
+	if err != nil {
+		return "", netip.Prefix{}, false, err
+	}
+	ifName, err := g.Network.InterfaceByMAC(mac)
+	if err != nil {
+		return "", netip.Prefix{}, false, fmt.Errorf("find interface for ENI %s (mac %s): %w", result.NetworkInterfaceID, mac, err)
+	}
+	// A /128 leaves the on-link subnet route to the primary address.
+	return ifName, netip.PrefixFrom(addr, addr.BitLen()), true, nil
+}
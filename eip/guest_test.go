@@ -0,0 +1,150 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+type fakeGuestNetwork struct {
+	links  map[string]string
+	addErr error
+	calls  []string
+}
+
+func (f *fakeGuestNetwork) InterfaceByMAC(mac string) (string, error) {
+	name, ok := f.links[mac]
+	if !ok {
+		return "", errors.New("no such link")
+	}
+	return name, nil
+}
+
+func (f *fakeGuestNetwork) AddAddress(ifName string, addr netip.Prefix) error {
+	f.calls = append(f.calls, "add "+ifName+" "+addr.String())
+	return f.addErr
+}
+
+func (f *fakeGuestNetwork) RemoveAddress(ifName string, addr netip.Prefix) error {
+	f.calls = append(f.calls, "remove "+ifName+" "+addr.String())
+	return nil
+}
+
+func guestMetadata() map[string]string {
+	return map[string]string{
+		"network/interfaces/macs/":                               "0e:00:00:00:00:01/\n0e:00:00:00:00:02/",
+		"network/interfaces/macs/0e:00:00:00:00:01/interface-id": "eni-secondary",
+		"network/interfaces/macs/0e:00:00:00:00:02/interface-id": "eni-primary",
+	}
+}
+
+func TestGuestConfigurator(t *testing.T) {
+	ipv6Result := &BindResult{
+		Family:             IPFamilyIPv6,
+		TargetIP:           "2001:db8::10",
+		NetworkInterfaceID: "eni-primary",
+	}
+
+	tests := []struct {
+		name          string
+		result        *BindResult
+		remove        bool
+		links         map[string]string
+		addErr        error
+		wantErr       bool
+		wantCalls     []string
+		wantIMDSCalls []string
+	}{
+		{
+			name:      "adds IPv6 host address to interface matched by MAC",
+			result:    ipv6Result,
+			links:     map[string]string{"0e:00:00:00:00:02": "ens5"},
+			wantCalls: []string{"add ens5 2001:db8::10/128"},
+			wantIMDSCalls: []string{
+				"GetMetadata:network/interfaces/macs/",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:01/interface-id",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:02/interface-id",
+			},
+		},
+		{
+			name:      "removes IPv6 address",
+			result:    ipv6Result,
+			remove:    true,
+			links:     map[string]string{"0e:00:00:00:00:02": "ens5"},
+			wantCalls: []string{"remove ens5 2001:db8::10/128"},
+			wantIMDSCalls: []string{
+				"GetMetadata:network/interfaces/macs/",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:01/interface-id",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:02/interface-id",
+			},
+		},
+		{
+			name:   "skips Elastic IPv4",
+			result: &BindResult{Family: IPFamilyIPv4, TargetIP: "54.162.153.80", NetworkInterfaceID: "eni-primary"},
+		},
+		{
+			name:   "skips delegated prefix",
+			result: &BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8:0:0:1::/80", NetworkInterfaceID: "eni-primary", Prefix: true},
+		},
+		{
+			name:    "ENI not attached",
+			result:  &BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8::10", NetworkInterfaceID: "eni-other"},
+			wantErr: true,
+			wantIMDSCalls: []string{
+				"GetMetadata:network/interfaces/macs/",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:01/interface-id",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:02/interface-id",
+			},
+		},
+		{
+			name:    "no local interface with MAC",
+			result:  ipv6Result,
+			wantErr: true,
+			wantIMDSCalls: []string{
+				"GetMetadata:network/interfaces/macs/",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:01/interface-id",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:02/interface-id",
+			},
+		},
+		{
+			name:      "add error",
+			result:    ipv6Result,
+			links:     map[string]string{"0e:00:00:00:00:02": "ens5"},
+			addErr:    errors.New("operation not permitted"),
+			wantErr:   true,
+			wantCalls: []string{"add ens5 2001:db8::10/128"},
+			wantIMDSCalls: []string{
+				"GetMetadata:network/interfaces/macs/",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:01/interface-id",
+				"GetMetadata:network/interfaces/macs/0e:00:00:00:00:02/interface-id",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := &fakeGuestNetwork{links: tt.links, addErr: tt.addErr}
+			imdsFake := newFakeIMDS(t, guestMetadata())
+			guest := NewGuestConfigurator(imdsFake, network, silentLogger())
+
+			var err error
+			if tt.remove {
+				err = guest.Deconfigure(context.Background(), tt.result)
+			} else {
+				err = guest.Configure(context.Background(), tt.result)
+			}
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(network.calls, tt.wantCalls) {
+				t.Fatalf("network calls = %v, want %v", network.calls, tt.wantCalls)
+			}
+			imdsFake.assertCalls(tt.wantIMDSCalls)
+		})
+	}
+}
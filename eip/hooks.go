@@ -0,0 +1,148 @@
+package eip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHookTimeout bounds each hook command.
+const DefaultHookTimeout = 30 * time.Second
+
+// Hook phases, passed to hooks as EIP_BINDING_PHASE and "phase".
+const (
+	HookPhasePreBind  = "pre-bind"
+	HookPhasePostBind = "post-bind"
+)
+
+// HookFailurePolicy decides what a failing pre-bind hook does to the bind.
+type HookFailurePolicy string
+
+const (
+	// HookFailureAbort fails the bind without touching the address.
+	HookFailureAbort HookFailurePolicy = "abort"
+	// HookFailureContinue logs the failure and binds anyway.
+	HookFailureContinue HookFailurePolicy = "continue"
+)
+
+// Hooks are shell commands run around Binder.Bind, for example to drain
+// traffic before an address moves and to reload services afterwards.
+//
+// Each command runs with "sh -c". The target and, after binding, the
+// BindResult are passed as EIP_BINDING_* environment variables and as a
+// HookEvent JSON document on stdin. Post-bind hooks run after every
+// successful Bind, including when the address was already associated;
+// their failures are logged but do not fail the bind.
+type Hooks struct {
+	PreBind  []string
+	PostBind []string
+	// Timeout bounds each command. Zero uses DefaultHookTimeout.
+	Timeout time.Duration
+	// PreBindFailure defaults to HookFailureAbort.
+	PreBindFailure HookFailurePolicy
+}
+
+// HookEvent is the JSON document hooks receive on stdin.
+type HookEvent struct {
+	Phase  string      `json:"phase"`
+	Target string      `json:"target"`
+	Result *BindResult `json:"result,omitempty"`
+}
+
+// ParseHookFailurePolicy validates a -pre-bind-hook-failure value.
+func ParseHookFailurePolicy(value string) (HookFailurePolicy, error) {
+	switch policy := HookFailurePolicy(value); policy {
+	case HookFailureAbort, HookFailureContinue:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid -pre-bind-hook-failure %q (want abort or continue)", value)
+	}
+}
+
+// runPreBind runs the pre-bind hooks in order. The first failure stops the
+// remaining hooks and is returned, unless PreBindFailure is
+// HookFailureContinue, in which case it is logged and the next hook runs.
+func (h *Hooks) runPreBind(ctx context.Context, logger *log.Logger, target string) error {
+	event := &HookEvent{Phase: HookPhasePreBind, Target: target}
+	for _, command := range h.PreBind {
+		if err := h.run(ctx, logger, command, event); err != nil {
+			if h.PreBindFailure != HookFailureContinue {
+				return err
+			}
+			logger.Printf("%v; continuing", err)
+		}
+	}
+	return nil
+}
+
+// runPostBind runs every post-bind hook, logging failures.
+func (h *Hooks) runPostBind(ctx context.Context, logger *log.Logger, target string, result *BindResult) {
+	event := &HookEvent{Phase: HookPhasePostBind, Target: target, Result: result}
+	for _, command := range h.PostBind {
+		if err := h.run(ctx, logger, command, event); err != nil {
+			logger.Printf("%v", err)
+		}
+	}
+}
+
+func (h *Hooks) run(ctx context.Context, logger *log.Logger, command string, event *HookEvent) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdin, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s hook %q: %w", event.Phase, command, err)
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), hookEnv(event)...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	// Do not wait on grandchildren that keep the output pipe open.
+	cmd.WaitDelay = time.Second
+
+	logger.Printf("Running %s hook: %s", event.Phase, command)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(string(output)); out != "" {
+			return fmt.Errorf("%s hook %q: %w: %s", event.Phase, command, err, out)
+		}
+		return fmt.Errorf("%s hook %q: %w", event.Phase, command, err)
+	}
+	return nil
+}
+
+// hookEnv returns the EIP_BINDING_* variables for event.
+func hookEnv(event *HookEvent) []string {
+	env := []string{
+		"EIP_BINDING_PHASE=" + event.Phase,
+		"EIP_BINDING_TARGET=" + event.Target,
+	}
+	if result := event.Result; result != nil {
+		env = append(env,
+			"EIP_BINDING_ALREADY_ASSOCIATED="+strconv.FormatBool(result.AlreadyAssociated),
+			"EIP_BINDING_ASSOCIATION_ID="+result.AssociationID,
+			"EIP_BINDING_INSTANCE_ID="+result.InstanceID,
+			"EIP_BINDING_FAMILY="+result.Family,
+			"EIP_BINDING_TARGET_IP="+result.TargetIP,
+			"EIP_BINDING_NETWORK_INTERFACE_ID="+result.NetworkInterfaceID,
+			"EIP_BINDING_PREFIX="+strconv.FormatBool(result.Prefix),
+			"EIP_BINDING_PRIMARY_IPV6="+strconv.FormatBool(result.PrimaryIPv6),
+			"EIP_BINDING_PREVIOUS_NETWORK_INTERFACE_ID="+result.PreviousNetworkInterfaceID,
+			"EIP_BINDING_PREVIOUS_INSTANCE_ID="+result.PreviousInstanceID,
+		)
+	}
+	return env
+}
@@ -0,0 +1,164 @@
+package eip
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestBindRunsHooks(t *testing.T) {
+	const (
+		targetIP   = "54.162.153.80"
+		instanceID = "i-hooks"
+	)
+	dir := t.TempDir()
+
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeAddresses = func(in *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		if _, err := os.Stat(filepath.Join(dir, "pre")); err != nil {
+			t.Errorf("pre-bind hook did not run before DescribeAddresses: %v", err)
+		}
+		return &ec2.DescribeAddressesOutput{
+			Addresses: []types.Address{elasticAddress(targetIP, "eipalloc-111", "")},
+		}, nil
+	}
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-primary")},
+			}, nil
+		},
+	}
+	ec2Fake.associateAddress = func(*ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+		return &ec2.AssociateAddressOutput{AssociationId: new("eipassoc-111")}, nil
+	}
+
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata(instanceID)), silentLogger())
+	binder.Hooks = &Hooks{
+		PreBind: []string{`echo "$EIP_BINDING_PHASE $EIP_BINDING_TARGET" > "` + dir + `/pre"`},
+		PostBind: []string{
+			`cat > "` + dir + `/post.json"`,
+			`echo "$EIP_BINDING_ASSOCIATION_ID $EIP_BINDING_NETWORK_INTERFACE_ID" > "` + dir + `/post.env"`,
+			"exit 3",
+		},
+	}
+	if _, err := binder.Bind(context.Background(), targetIP); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	requireFileContent(t, filepath.Join(dir, "pre"), "pre-bind 54.162.153.80")
+	requireFileContent(t, filepath.Join(dir, "post.env"), "eipassoc-111 eni-primary")
+	data, err := os.ReadFile(filepath.Join(dir, "post.json"))
+	if err != nil {
+		t.Fatalf("read post-bind stdin: %v", err)
+	}
+	var event HookEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("decode post-bind stdin %s: %v", data, err)
+	}
+	if event.Phase != HookPhasePostBind || event.Target != targetIP || event.Result == nil {
+		t.Fatalf("event = %+v, want post-bind event with result", event)
+	}
+	assertBindResult(t, event.Result, BindResult{
+		AssociationID:      "eipassoc-111",
+		InstanceID:         instanceID,
+		Family:             IPFamilyIPv4,
+		TargetIP:           targetIP,
+		NetworkInterfaceID: "eni-primary",
+	})
+}
+
+func TestBindPreBindHookFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		hooks    Hooks
+		wantErr  string
+		wantBind bool
+	}{
+		{
+			name:    "abort",
+			hooks:   Hooks{PreBind: []string{"echo draining failed; exit 1"}},
+			wantErr: "draining failed",
+		},
+		{
+			name:    "timeout aborts",
+			hooks:   Hooks{PreBind: []string{"sleep 5"}, Timeout: 50 * time.Millisecond},
+			wantErr: "timed out",
+		},
+		{
+			name:     "continue",
+			hooks:    Hooks{PreBind: []string{"exit 1"}, PreBindFailure: HookFailureContinue},
+			wantBind: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := newFakeEC2(t)
+			if tt.wantBind {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						return &ec2.DescribeNetworkInterfacesOutput{
+							NetworkInterfaces: []types.NetworkInterface{primaryENI("2001:db8::10")},
+						}, nil
+					},
+				}
+			}
+			binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata("i-hooks")), silentLogger())
+			binder.Hooks = &tt.hooks
+
+			_, err := binder.Bind(context.Background(), "2001:db8::10")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				ec2Fake.assertCalls(nil)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ec2Fake.assertCalls([]string{"DescribeNetworkInterfaces"})
+		})
+	}
+}
+
+func TestBindPreBindHookContinueRunsRemainingHooks(t *testing.T) {
+	dir := t.TempDir()
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{primaryENI("2001:db8::10")},
+			}, nil
+		},
+	}
+	binder := NewBinder(ec2Fake, newFakeIMDS(t, instanceMetadata("i-hooks")), silentLogger())
+	binder.Hooks = &Hooks{
+		PreBind:        []string{"exit 1", `echo drained > "` + dir + `/second"`},
+		PreBindFailure: HookFailureContinue,
+	}
+
+	if _, err := binder.Bind(context.Background(), "2001:db8::10"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requireFileContent(t, filepath.Join(dir, "second"), "drained")
+}
+
+func requireFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if got := strings.TrimSpace(string(data)); got != want {
+		t.Fatalf("%s = %q, want %q", filepath.Base(path), got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package eip
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	ec2imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+)
+
+// IMDSOptions configures the instance metadata client used for binding and,
+// through the SDK configuration, for instance role credentials.
+type IMDSOptions struct {
+	// Endpoint overrides the IMDS endpoint URL. It takes precedence over
+	// EndpointMode.
+	Endpoint string
+	// EndpointMode selects the IPv4 or IPv6 IMDS endpoint. Unset derives it
+	// from the network stack, or the target family when no stack is set.
+	EndpointMode ec2imds.EndpointModeState
+	// DisableV1 fails metadata requests when no IMDSv2 session token can be
+	// fetched instead of falling back to IMDSv1.
+	DisableV1 bool
+	// Timeout bounds each metadata request. Zero keeps the SDK default of
+	// five seconds.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts per metadata request, including
+	// the first. Zero keeps the SDK default.
+	MaxAttempts int
+}
+
+// parseIMDSOptions validates the -imds-* flags.
+func parseIMDSOptions(endpoint, endpointMode string, disableV1 bool, timeout time.Duration, maxAttempts int) (IMDSOptions, error) {
+	opts := IMDSOptions{
+		Endpoint:    endpoint,
+		DisableV1:   disableV1,
+		Timeout:     timeout,
+		MaxAttempts: maxAttempts,
+	}
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return IMDSOptions{}, fmt.Errorf("invalid -imds-endpoint %q (want an http or https URL)", endpoint)
+		}
+	}
+	if endpointMode != "" {
+		if err := opts.EndpointMode.SetFromString(endpointMode); err != nil {
+			return IMDSOptions{}, fmt.Errorf("invalid -imds-endpoint-mode %q (want ipv4 or ipv6)", endpointMode)
+		}
+	}
+	if timeout < 0 {
+		return IMDSOptions{}, fmt.Errorf("-imds-timeout must not be negative")
+	}
+	if maxAttempts < 0 {
+		return IMDSOptions{}, fmt.Errorf("-imds-max-attempts must not be negative")
+	}
+	return opts, nil
+}
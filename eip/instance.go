@@ -0,0 +1,86 @@
+package eip
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// InstanceSelector names the instance to bind to in off-instance mode: either
+// an instance ID or a tag that exactly one running instance carries.
+type InstanceSelector struct {
+	InstanceID string
+	TagKey     string
+	TagValue   string
+}
+
+// ParseInstanceSelector parses an -instance value: "i-0123456789abcdef0" or
+// "tag:KEY=VALUE".
+func ParseInstanceSelector(value string) (*InstanceSelector, error) {
+	if selector, ok := strings.CutPrefix(value, "tag:"); ok {
+		key, tagValue, ok := strings.Cut(selector, "=")
+		if !ok || key == "" || tagValue == "" {
+			return nil, fmt.Errorf("invalid instance tag %q (want tag:KEY=VALUE)", value)
+		}
+		return &InstanceSelector{TagKey: key, TagValue: tagValue}, nil
+	}
+	if !strings.HasPrefix(value, "i-") || len(value) == len("i-") {
+		return nil, fmt.Errorf("invalid instance %q (want an instance ID or tag:KEY=VALUE)", value)
+	}
+	return &InstanceSelector{InstanceID: value}, nil
+}
+
+func (s *InstanceSelector) String() string {
+	if s.InstanceID != "" {
+		return s.InstanceID
+	}
+	return "tag:" + s.TagKey + "=" + s.TagValue
+}
+
+// ResolveInstance returns the ID of the instance named by selector. A tag
+// selector must match exactly one running instance.
+func ResolveInstance(ctx context.Context, client EC2API, selector *InstanceSelector) (string, error) {
+	if selector.InstanceID != "" {
+		return selector.InstanceID, nil
+	}
+
+	paginator := ec2.NewDescribeInstancesPaginator(client, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{
+				Name:   new("tag:" + selector.TagKey),
+				Values: []string{selector.TagValue},
+			},
+			{
+				Name:   new("instance-state-name"),
+				Values: []string{string(types.InstanceStateNameRunning)},
+			},
+		},
+	})
+	var instanceIDs []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("describe instances tagged %s=%s: %w", selector.TagKey, selector.TagValue,
+				newAPICallError("DescribeInstances", err))
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.InstanceId != nil {
+					instanceIDs = append(instanceIDs, *instance.InstanceId)
+				}
+			}
+		}
+	}
+
+	switch len(instanceIDs) {
+	case 0:
+		return "", fmt.Errorf("no running instance tagged %s=%s", selector.TagKey, selector.TagValue)
+	case 1:
+		return instanceIDs[0], nil
+	default:
+		return "", newAmbiguousMatchError(fmt.Sprintf("running instances tagged %s=%s", selector.TagKey, selector.TagValue), instanceIDs)
+	}
+}
@@ -0,0 +1,86 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// InstanceTagTarget is the CLI target that reads the address from a tag on
+// the running instance.
+const InstanceTagTarget = "INSTANCE_TAG"
+
+// DefaultInstanceTag is the tag InstanceTagTarget reads unless -instance-tag
+// names another. It avoids "/" so it can be read from instance metadata.
+const DefaultInstanceTag = "eip-binding-address"
+
+// ErrInstanceTagNotFound is returned by ResolveInstanceTag when the instance
+// does not carry the tag.
+var ErrInstanceTagNotFound = errors.New("instance tag not found")
+
+// ResolveInstanceTag returns the value of the tag key on the running
+// instance. Instance metadata tags ("tags/instance/KEY") are tried first;
+// when they are disabled or key cannot be represented there, the tag is
+// looked up with DescribeTags instead.
+//
+// In off-instance mode instanceID names the instance and instance metadata is
+// not used; otherwise it is empty.
+func ResolveInstanceTag(ctx context.Context, client EC2API, imds MetadataClient, instanceID, key string) (string, error) {
+	if instanceID == "" {
+		if isMetadataTagKey(key) {
+			value, err := readMetadata(ctx, imds, "tags/instance/"+key)
+			if err == nil && strings.TrimSpace(value) != "" {
+				return strings.TrimSpace(value), nil
+			}
+		}
+
+		var err error
+		instanceID, err = readMetadata(ctx, imds, "instance-id")
+		if err != nil {
+			return "", fmt.Errorf("get instance ID: %w", err)
+		}
+	}
+	out, err := client.DescribeTags(ctx, &ec2.DescribeTagsInput{
+		Filters: []types.Filter{
+			{
+				Name:   new("resource-id"),
+				Values: []string{instanceID},
+			},
+			{
+				Name:   new("key"),
+				Values: []string{key},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("describe tags of instance %s: %w", instanceID, newAPICallError("DescribeTags", err, instanceID, key))
+	}
+	for _, tag := range out.Tags {
+		if tag.Key != nil && *tag.Key == key && tag.Value != nil {
+			if value := strings.TrimSpace(*tag.Value); value != "" {
+				return value, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: %s on instance %s", ErrInstanceTagNotFound, key, instanceID)
+}
+
+// isMetadataTagKey reports whether key can be read from instance metadata.
+// EC2 only exposes tags whose keys avoid "/", spaces, and other characters
+// outside [a-zA-Z0-9+=.,_:@-], and are not "." or "..".
+func isMetadataTagKey(key string) bool {
+	if key == "" || key == "." || key == ".." {
+		return false
+	}
+	return !strings.ContainsFunc(key, func(r rune) bool {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return false
+		}
+		return !strings.ContainsRune("+-=.,_:@", r)
+	})
+}
@@ -0,0 +1,113 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var errAccessDenied = errors.New("access denied")
+
+func TestResolveInstanceTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		instanceID  string
+		key         string
+		metadata    map[string]string
+		metadataErr map[string]error
+		tags        []types.TagDescription
+		describeErr error
+		want        string
+		wantErr     error
+		wantIMDS    []string
+		wantEC2     []string
+	}{
+		{
+			name:     "instance metadata tag",
+			key:      "eip-binding:address",
+			metadata: map[string]string{"tags/instance/eip-binding:address": "54.162.153.80\n"},
+			want:     "54.162.153.80",
+			wantIMDS: []string{"GetMetadata:tags/instance/eip-binding:address"},
+		},
+		{
+			name:     "default key is read from instance metadata",
+			key:      DefaultInstanceTag,
+			metadata: map[string]string{"tags/instance/" + DefaultInstanceTag: "54.162.153.80"},
+			want:     "54.162.153.80",
+			wantIMDS: []string{"GetMetadata:tags/instance/" + DefaultInstanceTag},
+		},
+		{
+			name:        "metadata tags disabled falls back to DescribeTags",
+			key:         "eip-binding:address",
+			metadata:    map[string]string{"instance-id": "i-self"},
+			metadataErr: map[string]error{"tags/instance/eip-binding:address": errors.New("404 not found")},
+			tags:        []types.TagDescription{{Key: new("eip-binding:address"), Value: new("2001:db8::1")}},
+			want:        "2001:db8::1",
+			wantIMDS:    []string{"GetMetadata:tags/instance/eip-binding:address", "GetMetadata:instance-id"},
+			wantEC2:     []string{"DescribeTags"},
+		},
+		{
+			name:     "key with slash skips instance metadata",
+			key:      "eip-binding/address",
+			metadata: map[string]string{"instance-id": "i-self"},
+			tags:     []types.TagDescription{{Key: new("eip-binding/address"), Value: new("54.162.153.80")}},
+			want:     "54.162.153.80",
+			wantIMDS: []string{"GetMetadata:instance-id"},
+			wantEC2:  []string{"DescribeTags"},
+		},
+		{
+			name:       "off-instance skips instance metadata",
+			instanceID: "i-self",
+			key:        "eip-binding:address",
+			tags:       []types.TagDescription{{Key: new("eip-binding:address"), Value: new("54.162.153.80")}},
+			want:       "54.162.153.80",
+			wantEC2:    []string{"DescribeTags"},
+		},
+		{
+			name:     "missing tag",
+			key:      "eip-binding/address",
+			metadata: map[string]string{"instance-id": "i-self"},
+			wantErr:  ErrInstanceTagNotFound,
+			wantIMDS: []string{"GetMetadata:instance-id"},
+			wantEC2:  []string{"DescribeTags"},
+		},
+		{
+			name:        "describe error",
+			key:         "eip-binding/address",
+			metadata:    map[string]string{"instance-id": "i-self"},
+			describeErr: errAccessDenied,
+			wantErr:     errAccessDenied,
+			wantIMDS:    []string{"GetMetadata:instance-id"},
+			wantEC2:     []string{"DescribeTags"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := newFakeEC2(t)
+			ec2Fake.describeTags = func(in *ec2.DescribeTagsInput) (*ec2.DescribeTagsOutput, error) {
+				requireFilter(t, in.Filters, "resource-id", "i-self")
+				requireFilter(t, in.Filters, "key", tt.key)
+				if tt.describeErr != nil {
+					return nil, tt.describeErr
+				}
+				return &ec2.DescribeTagsOutput{Tags: tt.tags}, nil
+			}
+			imdsFake := newFakeIMDS(t, tt.metadata)
+			imdsFake.metadataErr = tt.metadataErr
+
+			got, err := ResolveInstanceTag(context.Background(), ec2Fake, imdsFake, tt.instanceID, tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("target = %q, want %q", got, tt.want)
+			}
+			ec2Fake.assertCalls(tt.wantEC2)
+			imdsFake.assertCalls(tt.wantIMDS)
+		})
+	}
+}
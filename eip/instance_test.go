@@ -0,0 +1,135 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestParseInstanceSelector(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    InstanceSelector
+		wantErr bool
+	}{
+		{value: "i-0123456789abcdef0", want: InstanceSelector{InstanceID: "i-0123456789abcdef0"}},
+		{value: "tag:Name=web-primary", want: InstanceSelector{TagKey: "Name", TagValue: "web-primary"}},
+		{value: "tag:role=a=b", want: InstanceSelector{TagKey: "role", TagValue: "a=b"}},
+		{value: "tag:Name", wantErr: true},
+		{value: "tag:=web", wantErr: true},
+		{value: "i-", wantErr: true},
+		{value: "web-primary", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseInstanceSelector(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("selector = %+v, want %+v", *got, tt.want)
+			}
+			if got.String() != tt.value {
+				t.Fatalf("String() = %q, want %q", got.String(), tt.value)
+			}
+		})
+	}
+}
+
+func runningInstances(ids ...string) types.Reservation {
+	var reservation types.Reservation
+	for _, id := range ids {
+		reservation.Instances = append(reservation.Instances, types.Instance{InstanceId: new(id)})
+	}
+	return reservation
+}
+
+func TestResolveInstance(t *testing.T) {
+	tests := []struct {
+		name         string
+		selector     InstanceSelector
+		pages        []*ec2.DescribeInstancesOutput
+		err          error
+		want         string
+		wantErr      bool
+		wantEC2Calls []string
+	}{
+		{
+			name:     "instance ID needs no lookup",
+			selector: InstanceSelector{InstanceID: "i-web"},
+			want:     "i-web",
+		},
+		{
+			name:     "single tagged instance across pages",
+			selector: InstanceSelector{TagKey: "Name", TagValue: "web"},
+			pages: []*ec2.DescribeInstancesOutput{
+				{NextToken: new("page-2")},
+				{Reservations: []types.Reservation{runningInstances("i-web")}},
+			},
+			want:         "i-web",
+			wantEC2Calls: []string{"DescribeInstances", "DescribeInstances"},
+		},
+		{
+			name:         "no tagged instance",
+			selector:     InstanceSelector{TagKey: "Name", TagValue: "web"},
+			pages:        []*ec2.DescribeInstancesOutput{{}},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeInstances"},
+		},
+		{
+			name:     "ambiguous tag",
+			selector: InstanceSelector{TagKey: "Name", TagValue: "web"},
+			pages: []*ec2.DescribeInstancesOutput{
+				{Reservations: []types.Reservation{runningInstances("i-b"), runningInstances("i-a")}},
+			},
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeInstances"},
+		},
+		{
+			name:         "describe error",
+			selector:     InstanceSelector{TagKey: "Name", TagValue: "web"},
+			err:          errors.New("access denied"),
+			wantErr:      true,
+			wantEC2Calls: []string{"DescribeInstances"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := newFakeEC2(t)
+			ec2Fake.describeInstances = func(in *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+				requireFilter(t, in.Filters, "tag:Name", "web")
+				requireFilter(t, in.Filters, "instance-state-name", "running")
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				page := tt.pages[0]
+				tt.pages = tt.pages[1:]
+				return page, nil
+			}
+
+			got, err := ResolveInstance(context.Background(), ec2Fake, &tt.selector)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("instance ID = %q, want %q", got, tt.want)
+			}
+			ec2Fake.assertCalls(tt.wantEC2Calls)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package eip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultInterruptionInterval is how often InterruptionWatcher polls instance
+// metadata. Spot interruption notices arrive two minutes ahead, and AWS
+// recommends checking every five seconds.
+const DefaultInterruptionInterval = 5 * time.Second
+
+// Interruption notice kinds.
+const (
+	NoticeSpotInterruption        = "spot-interruption"
+	NoticeRebalanceRecommendation = "rebalance-recommendation"
+)
+
+const (
+	spotInstanceActionPath = "spot/instance-action"
+	rebalancePath          = "events/recommendations/rebalance"
+)
+
+// InterruptionNotice reports that the instance is about to be interrupted.
+type InterruptionNotice struct {
+	// Kind is NoticeSpotInterruption or NoticeRebalanceRecommendation.
+	Kind string
+	// Action is the spot interruption action: "terminate", "stop", or
+	// "hibernate". It is empty for rebalance recommendations.
+	Action string
+	// Time is when the interruption takes place, or when the rebalance
+	// recommendation was issued.
+	Time time.Time
+}
+
+func (n *InterruptionNotice) String() string {
+	if n.Action != "" {
+		return fmt.Sprintf("%s (%s at %s)", n.Kind, n.Action, n.Time.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s (at %s)", n.Kind, n.Time.Format(time.RFC3339))
+}
+
+// InterruptionWatcher polls instance metadata for spot interruption notices
+// and rebalance recommendations.
+type InterruptionWatcher struct {
+	IMDS     MetadataClient
+	Interval time.Duration
+	Logger   *log.Logger
+}
+
+// NewInterruptionWatcher creates an InterruptionWatcher.
+func NewInterruptionWatcher(imds MetadataClient, interval time.Duration, logger *log.Logger) *InterruptionWatcher {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if interval <= 0 {
+		interval = DefaultInterruptionInterval
+	}
+	return &InterruptionWatcher{
+		IMDS:     imds,
+		Interval: interval,
+		Logger:   logger,
+	}
+}
+
+// Watch polls until a notice appears and returns it, or returns nil when ctx
+// is cancelled first. Metadata errors are logged and retried.
+func (w *InterruptionWatcher) Watch(ctx context.Context) (*InterruptionNotice, error) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	w.Logger.Printf("Watching for spot interruption and rebalance notices every %s", w.Interval)
+	for {
+		notice, err := w.check(ctx)
+		if err != nil {
+			w.Logger.Printf("interruption: %v", err)
+		} else if notice != nil {
+			return notice, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check returns the pending notice, if any. Spot interruptions take
+// precedence over rebalance recommendations.
+func (w *InterruptionWatcher) check(ctx context.Context) (*InterruptionNotice, error) {
+	content, err := readMetadata(ctx, w.IMDS, spotInstanceActionPath)
+	if err == nil {
+		var action struct {
+			Action string    `json:"action"`
+			Time   time.Time `json:"time"`
+		}
+		if err := json.Unmarshal([]byte(content), &action); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", spotInstanceActionPath, err)
+		}
+		return &InterruptionNotice{Kind: NoticeSpotInterruption, Action: action.Action, Time: action.Time}, nil
+	}
+	if !isMetadataNotFound(err) {
+		return nil, err
+	}
+
+	content, err = readMetadata(ctx, w.IMDS, rebalancePath)
+	if err == nil {
+		var rebalance struct {
+			NoticeTime time.Time `json:"noticeTime"`
+		}
+		if err := json.Unmarshal([]byte(content), &rebalance); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", rebalancePath, err)
+		}
+		return &InterruptionNotice{Kind: NoticeRebalanceRecommendation, Time: rebalance.NoticeTime}, nil
+	}
+	if !isMetadataNotFound(err) {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// isMetadataNotFound reports whether err is the 404 instance metadata returns
+// for paths that are absent, such as notices that have not been issued.
+func isMetadataNotFound(err error) bool {
+	statusErr, ok := errors.AsType[interface {
+		error
+		HTTPStatusCode() int
+	}](err)
+	return ok && statusErr.HTTPStatusCode() == http.StatusNotFound
+}
+
+// InterruptionAction is what happens to the bound address when an
+// interruption notice arrives or the process is asked to stop.
+type InterruptionAction struct {
+	// Standby receives the address. When nil the address is unbound.
+	Standby *InstanceSelector
+}
+
+// ParseInterruptionAction parses an -on-interruption value: "unbind", or
+// "handoff:" followed by an instance ID or tag:KEY=VALUE naming the standby.
+func ParseInterruptionAction(value string) (*InterruptionAction, error) {
+	if value == "unbind" {
+		return &InterruptionAction{}, nil
+	}
+	if standby, ok := strings.CutPrefix(value, "handoff:"); ok {
+		selector, err := ParseInstanceSelector(standby)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -on-interruption standby: %w", err)
+		}
+		return &InterruptionAction{Standby: selector}, nil
+	}
+	return nil, fmt.Errorf("invalid -on-interruption %q (want unbind or handoff:INSTANCE)", value)
+}
+
+func (a *InterruptionAction) String() string {
+	if a.Standby == nil {
+		return "unbind"
+	}
+	return "handoff:" + a.Standby.String()
+}
+
+// Release applies action to the address in result. It unbinds the address,
+// or binds it to the standby instance and returns the standby's BindResult.
+// released reports whether the address left result's ENI; it is false when
+// an unbind found the address already gone.
+func (b *Binder) Release(ctx context.Context, result *BindResult, action *InterruptionAction) (next *BindResult, released bool, err error) {
+	if action.Standby == nil {
+		released, err := b.Unbind(ctx, result)
+		return nil, released, err
+	}
+
+	standbyID, err := ResolveInstance(ctx, b.EC2, action.Standby)
+	if err != nil {
+		return nil, false, fmt.Errorf("resolve standby instance: %w", err)
+	}
+	if standbyID == result.InstanceID {
+		return nil, false, fmt.Errorf("standby instance %s is the interrupted instance", standbyID)
+	}
+
+	b.Logger.Printf("Handing off %s %s from instance %s to standby %s", result.Family, result.TargetIP, result.InstanceID, standbyID)
+	standby := *b
+	standby.InstanceID = standbyID
+	next, err = standby.Bind(ctx, result.TargetIP)
+	return next, err == nil, err
+}
@@ -0,0 +1,310 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// metadataStatusError mimics the HTTP response errors returned by the IMDS
+// client.
+type metadataStatusError int
+
+func (e metadataStatusError) Error() string       { return fmt.Sprintf("http status %d", int(e)) }
+func (e metadataStatusError) HTTPStatusCode() int { return int(e) }
+
+var errMetadataNotFound = fmt.Errorf("get metadata: %w", metadataStatusError(404))
+
+func TestInterruptionWatcherCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    map[string]string
+		metadataErr map[string]error
+		want        *InterruptionNotice
+		wantErr     bool
+		wantCalls   []string
+	}{
+		{
+			name:        "no notice",
+			metadataErr: map[string]error{spotInstanceActionPath: errMetadataNotFound, rebalancePath: errMetadataNotFound},
+			wantCalls:   []string{"GetMetadata:" + spotInstanceActionPath, "GetMetadata:" + rebalancePath},
+		},
+		{
+			name:      "spot interruption",
+			metadata:  map[string]string{spotInstanceActionPath: `{"action": "terminate", "time": "2026-10-18T08:22:00Z"}`},
+			want:      &InterruptionNotice{Kind: NoticeSpotInterruption, Action: "terminate", Time: time.Date(2026, 10, 18, 8, 22, 0, 0, time.UTC)},
+			wantCalls: []string{"GetMetadata:" + spotInstanceActionPath},
+		},
+		{
+			name:        "rebalance recommendation",
+			metadata:    map[string]string{rebalancePath: `{"noticeTime": "2026-10-18T08:20:00Z"}`},
+			metadataErr: map[string]error{spotInstanceActionPath: errMetadataNotFound},
+			want:        &InterruptionNotice{Kind: NoticeRebalanceRecommendation, Time: time.Date(2026, 10, 18, 8, 20, 0, 0, time.UTC)},
+			wantCalls:   []string{"GetMetadata:" + spotInstanceActionPath, "GetMetadata:" + rebalancePath},
+		},
+		{
+			name:        "metadata unavailable",
+			metadataErr: map[string]error{spotInstanceActionPath: errors.New("connection refused")},
+			wantErr:     true,
+			wantCalls:   []string{"GetMetadata:" + spotInstanceActionPath},
+		},
+		{
+			name:      "malformed notice",
+			metadata:  map[string]string{spotInstanceActionPath: "terminate"},
+			wantErr:   true,
+			wantCalls: []string{"GetMetadata:" + spotInstanceActionPath},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imdsFake := newFakeIMDS(t, tt.metadata)
+			imdsFake.metadataErr = tt.metadataErr
+			watcher := NewInterruptionWatcher(imdsFake, time.Millisecond, silentLogger())
+
+			got, err := watcher.check(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("notice = %v, want %v", got, tt.want)
+			}
+			imdsFake.assertCalls(tt.wantCalls)
+		})
+	}
+}
+
+func TestInterruptionWatcherWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	imdsFake := newFakeIMDS(t, map[string]string{spotInstanceActionPath: `{"action": "stop", "time": "2026-10-18T08:22:00Z"}`})
+	watcher := NewInterruptionWatcher(imdsFake, time.Millisecond, silentLogger())
+	notice, err := watcher.Watch(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notice == nil || notice.Kind != NoticeSpotInterruption || notice.Action != "stop" {
+		t.Fatalf("notice = %v", notice)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	imdsFake = newFakeIMDS(t, nil)
+	imdsFake.metadataErr = map[string]error{spotInstanceActionPath: errMetadataNotFound, rebalancePath: errMetadataNotFound}
+	notice, err = NewInterruptionWatcher(imdsFake, time.Hour, silentLogger()).Watch(cancelled)
+	if notice != nil || err != nil {
+		t.Fatalf("Watch after cancel = %v, %v; want nil, nil", notice, err)
+	}
+}
+
+func TestParseInterruptionAction(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "unbind"},
+		{value: "handoff:i-0123456789abcdef0"},
+		{value: "handoff:tag:Name=web-standby"},
+		{value: "handoff:", wantErr: true},
+		{value: "handoff:web", wantErr: true},
+		{value: "release", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseInterruptionAction(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.value {
+				t.Fatalf("String() = %q, want %q", got.String(), tt.value)
+			}
+		})
+	}
+}
+
+func TestBinderUnbind(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       BindResult
+		setup        func(t *testing.T, ec2Fake *fakeEC2)
+		wantEC2Calls []string
+		wantReleased bool
+	}{
+		{
+			name:   "disassociates IPv4",
+			result: BindResult{Family: IPFamilyIPv4, TargetIP: "54.162.153.80", NetworkInterfaceID: "eni-primary"},
+			setup: func(t *testing.T, ec2Fake *fakeEC2) {
+				ec2Fake.describeAddresses = func(in *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+					requireDescribeAddressInput(t, in, "54.162.153.80")
+					address := elasticAddress("54.162.153.80", "eipalloc-111", "eipassoc-111")
+					address.NetworkInterfaceId = new("eni-primary")
+					return &ec2.DescribeAddressesOutput{Addresses: []types.Address{address}}, nil
+				}
+				ec2Fake.disassociateAddress = func(in *ec2.DisassociateAddressInput) (*ec2.DisassociateAddressOutput, error) {
+					requireStringPtr(t, in.AssociationId, "eipassoc-111", "AssociationId")
+					return &ec2.DisassociateAddressOutput{}, nil
+				}
+			},
+			wantEC2Calls: []string{"DescribeAddresses", "DisassociateAddress"},
+			wantReleased: true,
+		},
+		{
+			name:   "IPv4 already moved elsewhere",
+			result: BindResult{Family: IPFamilyIPv4, TargetIP: "54.162.153.80", NetworkInterfaceID: "eni-primary"},
+			setup: func(t *testing.T, ec2Fake *fakeEC2) {
+				ec2Fake.describeAddresses = func(in *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+					address := elasticAddress("54.162.153.80", "eipalloc-111", "eipassoc-222")
+					address.NetworkInterfaceId = new("eni-other")
+					return &ec2.DescribeAddressesOutput{Addresses: []types.Address{address}}, nil
+				}
+			},
+			wantEC2Calls: []string{"DescribeAddresses"},
+		},
+		{
+			name:   "unassigns IPv6 address",
+			result: BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8::1", NetworkInterfaceID: "eni-primary"},
+			setup: func(t *testing.T, ec2Fake *fakeEC2) {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{describeENIByID(t, primaryENI("2001:db8::1"))}
+				ec2Fake.unassignIPv6Addresses = func(in *ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error) {
+					requireStringPtr(t, in.NetworkInterfaceId, "eni-primary", "NetworkInterfaceId")
+					requireStrings(t, in.Ipv6Addresses, []string{"2001:db8::1"}, "Ipv6Addresses")
+					requireStrings(t, in.Ipv6Prefixes, nil, "Ipv6Prefixes")
+					return &ec2.UnassignIpv6AddressesOutput{}, nil
+				}
+			},
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "UnassignIpv6Addresses"},
+			wantReleased: true,
+		},
+		{
+			name:   "IPv6 address already moved elsewhere",
+			result: BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8::1", NetworkInterfaceID: "eni-primary"},
+			setup: func(t *testing.T, ec2Fake *fakeEC2) {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{describeENIByID(t, primaryENI("2001:db8::2"))}
+			},
+			wantEC2Calls: []string{"DescribeNetworkInterfaces"},
+		},
+		{
+			name:   "unassigns IPv6 prefix",
+			result: BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8:0:0:1::/80", NetworkInterfaceID: "eni-primary", Prefix: true},
+			setup: func(t *testing.T, ec2Fake *fakeEC2) {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+					describeENIByID(t, withIPv6Prefixes(primaryENI(), "2001:db8:0:0:1::/80")),
+				}
+				ec2Fake.unassignIPv6Addresses = func(in *ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error) {
+					requireStrings(t, in.Ipv6Addresses, nil, "Ipv6Addresses")
+					requireStrings(t, in.Ipv6Prefixes, []string{"2001:db8:0:0:1::/80"}, "Ipv6Prefixes")
+					return &ec2.UnassignIpv6AddressesOutput{}, nil
+				}
+			},
+			wantEC2Calls: []string{"DescribeNetworkInterfaces", "UnassignIpv6Addresses"},
+			wantReleased: true,
+		},
+		{
+			name:   "IPv6 prefix already moved elsewhere",
+			result: BindResult{Family: IPFamilyIPv6, TargetIP: "2001:db8:0:0:1::/80", NetworkInterfaceID: "eni-primary", Prefix: true},
+			setup: func(t *testing.T, ec2Fake *fakeEC2) {
+				ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{describeENIByID(t, primaryENI())}
+			},
+			wantEC2Calls: []string{"DescribeNetworkInterfaces"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := newFakeEC2(t)
+			tt.setup(t, ec2Fake)
+			binder := NewBinder(ec2Fake, newFakeIMDS(t, nil), silentLogger())
+
+			released, err := binder.Unbind(context.Background(), &tt.result)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if released != tt.wantReleased {
+				t.Fatalf("released = %v, want %v", released, tt.wantReleased)
+			}
+			ec2Fake.assertCalls(tt.wantEC2Calls)
+		})
+	}
+}
+
+// describeENIByID answers a DescribeNetworkInterfaces call for eni's ID.
+func describeENIByID(t *testing.T, eni types.NetworkInterface) describeNetworkInterfacesFunc {
+	return func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+		requireStrings(t, in.NetworkInterfaceIds, []string{*eni.NetworkInterfaceId}, "NetworkInterfaceIds")
+		return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: []types.NetworkInterface{eni}}, nil
+	}
+}
+
+func TestBinderReleaseHandoff(t *testing.T) {
+	const targetIP = "54.162.153.80"
+
+	ec2Fake := newFakeEC2(t)
+	ec2Fake.describeAddresses = func(in *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+		requireDescribeAddressInput(t, in, targetIP)
+		address := elasticAddress(targetIP, "eipalloc-111", "eipassoc-111")
+		address.NetworkInterfaceId = new("eni-primary")
+		return &ec2.DescribeAddressesOutput{Addresses: []types.Address{address}}, nil
+	}
+	ec2Fake.describeNetworkInterfaces = []describeNetworkInterfacesFunc{
+		func(in *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+			requirePrimaryENIFilters(t, in, "i-standby")
+			return &ec2.DescribeNetworkInterfacesOutput{
+				NetworkInterfaces: []types.NetworkInterface{networkInterface("eni-standby")},
+			}, nil
+		},
+	}
+	ec2Fake.associateAddress = func(in *ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+		requireStringPtr(t, in.NetworkInterfaceId, "eni-standby", "NetworkInterfaceId")
+		return &ec2.AssociateAddressOutput{AssociationId: new("eipassoc-standby")}, nil
+	}
+	imdsFake := newFakeIMDS(t, nil)
+	binder := NewBinder(ec2Fake, imdsFake, silentLogger())
+
+	got, released, err := binder.Release(context.Background(), &BindResult{
+		InstanceID:         "i-spot",
+		Family:             IPFamilyIPv4,
+		TargetIP:           targetIP,
+		NetworkInterfaceID: "eni-primary",
+	}, &InterruptionAction{Standby: &InstanceSelector{InstanceID: "i-standby"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !released {
+		t.Fatal("released = false after a handoff")
+	}
+	assertBindResult(t, got, BindResult{
+		AssociationID:              "eipassoc-standby",
+		InstanceID:                 "i-standby",
+		Family:                     IPFamilyIPv4,
+		TargetIP:                   targetIP,
+		NetworkInterfaceID:         "eni-standby",
+		PreviousNetworkInterfaceID: "eni-primary",
+	})
+	if binder.InstanceID != "" {
+		t.Fatalf("Release changed the binder's instance to %q", binder.InstanceID)
+	}
+	ec2Fake.assertCalls([]string{"DescribeAddresses", "DescribeNetworkInterfaces", "AssociateAddress"})
+	imdsFake.assertCalls(nil)
+
+	if _, _, err := binder.Release(context.Background(), &BindResult{InstanceID: "i-standby"},
+		&InterruptionAction{Standby: &InstanceSelector{InstanceID: "i-standby"}}); err == nil {
+		t.Fatal("expected error handing off to the interrupted instance, got nil")
+	}
+}
@@ -2,6 +2,9 @@ package eip
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 
 	ec2imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 )
@@ -10,3 +13,46 @@ import (
 type MetadataClient interface {
 	GetMetadata(ctx context.Context, params *ec2imds.GetMetadataInput, optFns ...func(*ec2imds.Options)) (*ec2imds.GetMetadataOutput, error)
 }
+
+// CurrentInstanceID returns the ID of the instance the process runs on.
+func CurrentInstanceID(ctx context.Context, client MetadataClient) (string, error) {
+	return readMetadata(ctx, client, "instance-id")
+}
+
+// readMetadata returns the content of the instance metadata path.
+func readMetadata(ctx context.Context, client MetadataClient, path string) (string, error) {
+	out, err := client.GetMetadata(ctx, &ec2imds.GetMetadataInput{Path: path})
+	if err != nil {
+		return "", fmt.Errorf("get %s: %w", path, err)
+	}
+	if out == nil || out.Content == nil {
+		return "", fmt.Errorf("get %s: empty metadata response", path)
+	}
+	defer out.Content.Close() //nolint:errcheck
+
+	content, err := io.ReadAll(out.Content)
+	if err != nil {
+		return "", fmt.Errorf("get %s: %w", path, err)
+	}
+	return string(content), nil
+}
+
+// macForNetworkInterface returns the MAC address IMDS reports for the ENI
+// attached to this instance with the given ID.
+func macForNetworkInterface(ctx context.Context, client MetadataClient, networkInterfaceID string) (string, error) {
+	macs, err := readMetadata(ctx, client, "network/interfaces/macs/")
+	if err != nil {
+		return "", err
+	}
+	for mac := range strings.FieldsSeq(macs) {
+		mac = strings.TrimSuffix(mac, "/")
+		id, err := readMetadata(ctx, client, "network/interfaces/macs/"+mac+"/interface-id")
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(id) == networkInterfaceID {
+			return mac, nil
+		}
+	}
+	return "", fmt.Errorf("network interface %s is not attached to this instance according to instance metadata", networkInterfaceID)
+}
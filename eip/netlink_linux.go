@@ -0,0 +1,73 @@
+package eip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// netlinkNetwork uses the package-level netlink functions, which open a
+// socket per request, so there is no long-lived handle to close.
+type netlinkNetwork struct{}
+
+// NewNetlinkNetwork returns a GuestNetwork that configures interfaces in the
+// calling thread's network namespace through netlink.
+func NewNetlinkNetwork() (GuestNetwork, error) {
+	return &netlinkNetwork{}, nil
+}
+
+func (n *netlinkNetwork) InterfaceByMAC(mac string) (string, error) {
+	want, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", fmt.Errorf("invalid MAC address %q: %w", mac, err)
+	}
+	links, err := netlink.LinkList()
+	if err != nil {
+		return "", fmt.Errorf("list links: %w", err)
+	}
+	for _, link := range links {
+		if strings.EqualFold(link.Attrs().HardwareAddr.String(), want.String()) {
+			return link.Attrs().Name, nil
+		}
+	}
+	return "", fmt.Errorf("no interface with MAC address %s", want)
+}
+
+func (n *netlinkNetwork) AddAddress(ifName string, addr netip.Prefix) error {
+	link, err := netlink.LinkByName(ifName)
+	if err != nil {
+		return err
+	}
+	nlAddr := netlinkAddr(addr)
+	if addr.Addr().Is6() {
+		// EC2 already guarantees the address is unique within the VPC.
+		nlAddr.Flags = unix.IFA_F_NODAD
+	}
+	return netlink.AddrReplace(link, nlAddr)
+}
+
+func (n *netlinkNetwork) RemoveAddress(ifName string, addr netip.Prefix) error {
+	link, err := netlink.LinkByName(ifName)
+	if err != nil {
+		return err
+	}
+	err = netlink.AddrDel(link, netlinkAddr(addr))
+	if errors.Is(err, unix.EADDRNOTAVAIL) || errors.Is(err, unix.ENOENT) {
+		return nil
+	}
+	return err
+}
+
+func netlinkAddr(addr netip.Prefix) *netlink.Addr {
+	return &netlink.Addr{
+		IPNet: &net.IPNet{
+			IP:   addr.Addr().AsSlice(),
+			Mask: net.CIDRMask(addr.Bits(), addr.Addr().BitLen()),
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package eip
+
+import (
+	"net/netip"
+	"runtime"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// TestNetlinkNetwork exercises the real netlink implementation inside a
+// throwaway network namespace. It needs CAP_NET_ADMIN and is skipped otherwise.
+func TestNetlinkNetwork(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origin, err := netns.Get()
+	if err != nil {
+		t.Skipf("get current netns: %v", err)
+	}
+	defer origin.Close() //nolint:errcheck
+	ns, err := netns.New()
+	if err != nil {
+		t.Skipf("create netns (needs CAP_NET_ADMIN): %v", err)
+	}
+	defer ns.Close()        //nolint:errcheck
+	defer netns.Set(origin) //nolint:errcheck
+
+	dummy := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eip0"}}
+	if err := netlink.LinkAdd(dummy); err != nil {
+		t.Skipf("add dummy link: %v", err)
+	}
+	if err := netlink.LinkSetUp(dummy); err != nil {
+		t.Fatalf("set link up: %v", err)
+	}
+	link, err := netlink.LinkByName("eip0")
+	if err != nil {
+		t.Fatalf("get link: %v", err)
+	}
+
+	network, err := NewNetlinkNetwork()
+	if err != nil {
+		t.Fatalf("NewNetlinkNetwork: %v", err)
+	}
+	ifName, err := network.InterfaceByMAC(link.Attrs().HardwareAddr.String())
+	if err != nil {
+		t.Fatalf("InterfaceByMAC: %v", err)
+	}
+	if ifName != "eip0" {
+		t.Fatalf("interface = %q, want eip0", ifName)
+	}
+
+	addr := netip.MustParsePrefix("2001:db8::10/128")
+	for range 2 {
+		if err := network.AddAddress(ifName, addr); err != nil {
+			t.Fatalf("AddAddress: %v", err)
+		}
+	}
+	if !linkHasAddress(t, link, addr) {
+		t.Fatalf("address %s not on link after AddAddress", addr)
+	}
+	for range 2 {
+		if err := network.RemoveAddress(ifName, addr); err != nil {
+			t.Fatalf("RemoveAddress: %v", err)
+		}
+	}
+	if linkHasAddress(t, link, addr) {
+		t.Fatalf("address %s still on link after RemoveAddress", addr)
+	}
+}
+
+func linkHasAddress(t *testing.T, link netlink.Link, want netip.Prefix) bool {
+	t.Helper()
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V6)
+	if err != nil {
+		t.Fatalf("list addresses: %v", err)
+	}
+	for _, addr := range addrs {
+		got, ok := netip.AddrFromSlice(addr.IP)
+		if ok && got == want.Addr() {
+			return true
+		}
+	}
+	return false
+}
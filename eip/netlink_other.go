@@ -0,0 +1,13 @@
+//go:build !linux
+
+package eip
+
+import (
+	"errors"
+	"runtime"
+)
+
+// NewNetlinkNetwork returns an error on platforms without netlink.
+func NewNetlinkNetwork() (GuestNetwork, error) {
+	return nil, errors.New("guest OS configuration is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,160 @@
+package eip
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NetworkStack selects which IP versions the instance metadata and EC2 API
+// endpoints are reached over, independently of the target's family.
+type NetworkStack string
+
+const (
+	// NetworkStackFamily keeps the historical behavior: IPv6 targets use the
+	// IPv6 IMDS endpoint and dual-stack EC2 endpoints, IPv4 targets use the
+	// SDK defaults.
+	NetworkStackFamily NetworkStack = ""
+	// NetworkStackAuto detects the stack from the local interfaces.
+	NetworkStackAuto NetworkStack = "auto"
+	// NetworkStackIPv4 uses the IPv4 IMDS endpoint and IPv4-only EC2
+	// endpoints.
+	NetworkStackIPv4 NetworkStack = "ipv4"
+	// NetworkStackIPv6 uses the IPv6 IMDS endpoint and dual-stack EC2
+	// endpoints, which are reachable over IPv6.
+	NetworkStackIPv6 NetworkStack = "ipv6"
+	// NetworkStackDualStack uses the IPv4 IMDS endpoint and dual-stack EC2
+	// endpoints.
+	NetworkStackDualStack NetworkStack = "dual-stack"
+)
+
+// ParseNetworkStack parses a -network-stack value.
+func ParseNetworkStack(value string) (NetworkStack, error) {
+	switch stack := NetworkStack(value); stack {
+	case NetworkStackFamily, NetworkStackAuto, NetworkStackIPv4, NetworkStackIPv6, NetworkStackDualStack:
+		return stack, nil
+	default:
+		return "", fmt.Errorf("invalid -network-stack %q (want auto, ipv4, ipv6, or dual-stack)", value)
+	}
+}
+
+// DetectNetworkStack picks the stack from the global unicast addresses on the
+// local interfaces that are up. Where the routing table can be read, an
+// address only counts on an interface holding the default route of its
+// family, so bridges such as docker0 do not add a family the instance cannot
+// route.
+func DetectNetworkStack() (NetworkStack, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("list interfaces: %w", err)
+	}
+	addrs := make(map[string][]netip.Addr)
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return "", fmt.Errorf("list addresses of %s: %w", iface.Name, err)
+		}
+		for _, addr := range ifaceAddrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				if ip, ok := netip.AddrFromSlice(ipNet.IP); ok {
+					addrs[iface.Name] = append(addrs[iface.Name], ip.Unmap())
+				}
+			}
+		}
+	}
+	// Without a readable routing table every interface counts.
+	routes, _ := readDefaultRoutes()
+	return networkStackFromAddrs(addrs, routes)
+}
+
+// defaultRoutes names the interfaces holding a default route per family.
+type defaultRoutes struct {
+	ipv4 []string
+	ipv6 []string
+}
+
+// networkStackFromAddrs picks the stack from the addresses of each
+// interface. IPv6 unique local addresses never count. With routes, an
+// address only counts on an interface holding the default route of its
+// family.
+func networkStackFromAddrs(addrs map[string][]netip.Addr, routes *defaultRoutes) (NetworkStack, error) {
+	var hasIPv4, hasIPv6 bool
+	for iface, ifaceAddrs := range addrs {
+		for _, addr := range ifaceAddrs {
+			if !addr.IsGlobalUnicast() {
+				continue
+			}
+			if addr.Is4() {
+				hasIPv4 = hasIPv4 || routes == nil || slices.Contains(routes.ipv4, iface)
+			} else if !addr.IsPrivate() {
+				hasIPv6 = hasIPv6 || routes == nil || slices.Contains(routes.ipv6, iface)
+			}
+		}
+	}
+	switch {
+	case hasIPv4 && hasIPv6:
+		return NetworkStackDualStack, nil
+	case hasIPv6:
+		return NetworkStackIPv6, nil
+	case hasIPv4:
+		return NetworkStackIPv4, nil
+	default:
+		return "", fmt.Errorf("no routable IPv4 or IPv6 address on any interface to detect the network stack from")
+	}
+}
+
+// Route flags from linux/route.h.
+const (
+	routeFlagUp     = 0x0001
+	routeFlagReject = 0x0200
+)
+
+// parseDefaultRoutes reads the interfaces of the usable default routes from
+// the formats of /proc/net/route and /proc/net/ipv6_route.
+func parseDefaultRoutes(ipv4, ipv6 io.Reader) (*defaultRoutes, error) {
+	routes := &defaultRoutes{}
+	// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+	scanner := bufio.NewScanner(ipv4)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		if usableRoute(fields[3]) {
+			routes.ipv4 = append(routes.ipv4, fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// Destination DestLen Source SourceLen NextHop Metric RefCnt Use Flags Iface
+	scanner = bufio.NewScanner(ipv6)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || strings.Trim(fields[0], "0") != "" || fields[1] != "00" || fields[9] == "lo" {
+			continue
+		}
+		if usableRoute(fields[8]) {
+			routes.ipv6 = append(routes.ipv6, fields[9])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// usableRoute reports whether the hex route flags mark a route that is up
+// and not a reject route.
+func usableRoute(hexFlags string) bool {
+	flags, err := strconv.ParseUint(hexFlags, 16, 32)
+	return err == nil && flags&routeFlagUp != 0 && flags&routeFlagReject == 0
+}
@@ -0,0 +1,20 @@
+package eip
+
+import (
+	"os"
+)
+
+// readDefaultRoutes reads the default routes from procfs.
+func readDefaultRoutes() (*defaultRoutes, error) {
+	ipv4, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	defer ipv4.Close()
+	ipv6, err := os.Open("/proc/net/ipv6_route")
+	if err != nil {
+		return nil, err
+	}
+	defer ipv6.Close()
+	return parseDefaultRoutes(ipv4, ipv6)
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package eip
+
+import (
+	"errors"
+	"runtime"
+)
+
+// readDefaultRoutes reports that the routing table is not read on this
+// platform, so every interface counts.
+func readDefaultRoutes() (*defaultRoutes, error) {
+	return nil, errors.New("reading default routes is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,87 @@
+package eip
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestNetworkStackFromAddrs(t *testing.T) {
+	tests := []struct {
+		name    string
+		addrs   map[string][]string
+		routes  *defaultRoutes
+		want    NetworkStack
+		wantErr bool
+	}{
+		{name: "IPv4 only", addrs: map[string][]string{"ens5": {"10.0.1.5", "fe80::1"}}, want: NetworkStackIPv4},
+		{name: "IPv6 only", addrs: map[string][]string{"ens5": {"2001:db8::5", "169.254.0.2"}}, want: NetworkStackIPv6},
+		{name: "dual stack", addrs: map[string][]string{"ens5": {"10.0.1.5", "2001:db8::5"}}, want: NetworkStackDualStack},
+		{name: "link-local only", addrs: map[string][]string{"ens5": {"169.254.0.2", "fe80::1"}}, wantErr: true},
+		{
+			name:  "IPv6 unique local address",
+			addrs: map[string][]string{"ens5": {"10.0.1.5"}, "docker0": {"fd00:dead:beef::1"}},
+			want:  NetworkStackIPv4,
+		},
+		{
+			name:   "docker bridge on an IPv6-only host",
+			addrs:  map[string][]string{"ens5": {"2001:db8::5"}, "docker0": {"172.17.0.1"}},
+			routes: &defaultRoutes{ipv6: []string{"ens5"}},
+			want:   NetworkStackIPv6,
+		},
+		{
+			name:   "docker bridge on a dual-stack host",
+			addrs:  map[string][]string{"ens5": {"10.0.1.5", "2001:db8::5"}, "docker0": {"172.17.0.1"}},
+			routes: &defaultRoutes{ipv4: []string{"ens5"}, ipv6: []string{"ens5"}},
+			want:   NetworkStackDualStack,
+		},
+		{
+			name:    "no default route",
+			addrs:   map[string][]string{"ens5": {"10.0.1.5"}},
+			routes:  &defaultRoutes{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addrs := make(map[string][]netip.Addr)
+			for iface, ifaceAddrs := range tt.addrs {
+				for _, addr := range ifaceAddrs {
+					addrs[iface] = append(addrs[iface], netip.MustParseAddr(addr))
+				}
+			}
+			got, err := networkStackFromAddrs(addrs, tt.routes)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("stack = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDefaultRoutes(t *testing.T) {
+	ipv4 := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+ens5	00000000	0100000A	0003	0	0	0	00000000	0	0	0
+ens5	0000000A	00000000	0001	0	0	0	00FFFFFF	0	0	0
+docker0	000011AC	00000000	0001	0	0	0	0000FFFF	0	0	0
+`
+	ipv6 := `20010db8000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     ens5
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe800000000000000000000000000001 00000400 00000001 00000000 00000003     ens5
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200       lo
+`
+	routes, err := parseDefaultRoutes(strings.NewReader(ipv4), strings.NewReader(ipv6))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requireStrings(t, routes.ipv4, []string{"ens5"}, "IPv4 default routes")
+	requireStrings(t, routes.ipv6, []string{"ens5"}, "IPv6 default routes")
+}
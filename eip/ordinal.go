@@ -0,0 +1,164 @@
+package eip
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// PodOrdinalTarget is the CLI target that picks an address from a list by
+// the StatefulSet ordinal at the end of POD_NAME. The list comes from
+// -ordinal-addresses: "env:NAME", "file:PATH", a literal comma-separated
+// list, or "tag:KEY=VALUE" for an AddressPool.
+const PodOrdinalTarget = "POD_ORDINAL"
+
+// AddressPool selects Elastic IPs by tag for POD_ORDINAL targets. Matching
+// addresses are sorted by the value of SortTag and Index picks one of them.
+type AddressPool struct {
+	TagKey   string
+	TagValue string
+	SortTag  string
+	Index    int
+}
+
+// ParsePodOrdinal returns the StatefulSet ordinal of podName, e.g. 2 for "web-2".
+func ParsePodOrdinal(podName string) (int, error) {
+	i := strings.LastIndexByte(podName, '-')
+	if i < 0 {
+		return 0, fmt.Errorf("pod name %q has no StatefulSet ordinal suffix", podName)
+	}
+	suffix := podName[i+1:]
+	if suffix == "" || strings.ContainsFunc(suffix, func(r rune) bool { return r < '0' || r > '9' }) {
+		return 0, fmt.Errorf("pod name %q has no StatefulSet ordinal suffix", podName)
+	}
+	ordinal, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 0, fmt.Errorf("pod name %q: %w", podName, err)
+	}
+	return ordinal, nil
+}
+
+// parseAddressPool parses the "KEY=VALUE" tag selector of a tag: source.
+func parseAddressPool(selector, sortTag string, index int) (*AddressPool, error) {
+	key, value, ok := strings.Cut(selector, "=")
+	if !ok || key == "" || value == "" {
+		return nil, fmt.Errorf("invalid address pool tag %q (want KEY=VALUE)", selector)
+	}
+	if sortTag == "" {
+		return nil, fmt.Errorf("address pool tag:%s requires -ordinal-sort-tag", selector)
+	}
+	return &AddressPool{TagKey: key, TagValue: value, SortTag: sortTag, Index: index}, nil
+}
+
+// readAddressList reads an ordinal address list from source. A source is
+// "env:NAME", "file:PATH", or a literal comma-separated list. Lists may be
+// separated by commas or newlines; blank entries and "#" comments are skipped.
+func readAddressList(source string, getenv func(string) string) ([]string, error) {
+	var raw string
+	switch {
+	case strings.HasPrefix(source, "env:"):
+		name := strings.TrimPrefix(source, "env:")
+		raw = getenv(name)
+		if raw == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", name)
+		}
+	case strings.HasPrefix(source, "file:"):
+		path := strings.TrimPrefix(source, "file:")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read address list: %w", err)
+		}
+		raw = string(data)
+	default:
+		raw = source
+	}
+
+	var addresses []string
+	for line := range strings.Lines(raw) {
+		line, _, _ = strings.Cut(line, "#")
+		for entry := range strings.SplitSeq(line, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				addresses = append(addresses, entry)
+			}
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("address list %s is empty", source)
+	}
+	return addresses, nil
+}
+
+// ResolveAddressPool returns the Elastic IP at pool.Index among the addresses
+// tagged pool.TagKey=pool.TagValue, ordered by their pool.SortTag value.
+// Sort values compare numerically when they are all integers, and must be
+// unique so every ordinal maps to a different address.
+func ResolveAddressPool(ctx context.Context, client EC2API, pool *AddressPool) (string, error) {
+	out, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+		Filters: []types.Filter{
+			{
+				Name:   new("tag:" + pool.TagKey),
+				Values: []string{pool.TagValue},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("describe addresses tagged %s=%s: %w", pool.TagKey, pool.TagValue,
+			newAPICallError("DescribeAddresses", err, pool.TagKey+"="+pool.TagValue))
+	}
+
+	type member struct {
+		ip      string
+		sortKey string
+	}
+	members := make([]member, 0, len(out.Addresses))
+	numeric := true
+	for _, addr := range out.Addresses {
+		if addr.PublicIp == nil {
+			continue
+		}
+		sortKey, ok := tagValue(addr.Tags, pool.SortTag)
+		if !ok {
+			return "", fmt.Errorf("address %s in pool %s=%s has no %s tag", *addr.PublicIp, pool.TagKey, pool.TagValue, pool.SortTag)
+		}
+		if _, err := strconv.Atoi(sortKey); err != nil {
+			numeric = false
+		}
+		members = append(members, member{ip: *addr.PublicIp, sortKey: sortKey})
+	}
+
+	compare := func(a, b member) int {
+		if numeric {
+			x, _ := strconv.Atoi(a.sortKey)
+			y, _ := strconv.Atoi(b.sortKey)
+			return cmp.Compare(x, y)
+		}
+		return cmp.Compare(a.sortKey, b.sortKey)
+	}
+	slices.SortFunc(members, compare)
+	for i := 1; i < len(members); i++ {
+		if compare(members[i-1], members[i]) == 0 {
+			return "", fmt.Errorf("addresses %s and %s in pool %s=%s have the same %s tag %q",
+				members[i-1].ip, members[i].ip, pool.TagKey, pool.TagValue, pool.SortTag, members[i].sortKey)
+		}
+	}
+	if pool.Index >= len(members) {
+		return "", fmt.Errorf("ordinal %d is out of range for pool %s=%s with %d addresses", pool.Index, pool.TagKey, pool.TagValue, len(members))
+	}
+	return members[pool.Index].ip, nil
+}
+
+func tagValue(tags []types.Tag, key string) (string, bool) {
+	for _, tag := range tags {
+		if tag.Key != nil && *tag.Key == key && tag.Value != nil {
+			return *tag.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,142 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestParsePodOrdinal(t *testing.T) {
+	tests := []struct {
+		podName string
+		want    int
+		wantErr bool
+	}{
+		{podName: "web-0", want: 0},
+		{podName: "my-app-12", want: 12},
+		{podName: "web", wantErr: true},
+		{podName: "web-abc", wantErr: true},
+		{podName: "web-+1", wantErr: true},
+		{podName: "web-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.podName, func(t *testing.T) {
+			got, err := ParsePodOrdinal(tt.podName)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ordinal = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func taggedAddress(publicIP string, tags map[string]string) types.Address {
+	addr := elasticAddress(publicIP, "eipalloc-"+publicIP, "")
+	for key, value := range tags {
+		addr.Tags = append(addr.Tags, types.Tag{Key: new(key), Value: new(value)})
+	}
+	return addr
+}
+
+func TestResolveAddressPool(t *testing.T) {
+	tests := []struct {
+		name      string
+		addresses []types.Address
+		err       error
+		index     int
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "numeric sort tag",
+			addresses: []types.Address{
+				taggedAddress("54.0.0.10", map[string]string{"slot": "10"}),
+				taggedAddress("54.0.0.2", map[string]string{"slot": "2"}),
+				taggedAddress("54.0.0.1", map[string]string{"slot": "1"}),
+			},
+			index: 1,
+			want:  "54.0.0.2",
+		},
+		{
+			name: "lexical sort tag",
+			addresses: []types.Address{
+				taggedAddress("54.0.0.2", map[string]string{"slot": "b"}),
+				taggedAddress("54.0.0.1", map[string]string{"slot": "a"}),
+			},
+			index: 0,
+			want:  "54.0.0.1",
+		},
+		{
+			name: "missing sort tag",
+			addresses: []types.Address{
+				taggedAddress("54.0.0.1", map[string]string{"slot": "0"}),
+				taggedAddress("54.0.0.2", nil),
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate sort tag",
+			addresses: []types.Address{
+				taggedAddress("54.0.0.1", map[string]string{"slot": "1"}),
+				taggedAddress("54.0.0.2", map[string]string{"slot": "01"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "index out of range",
+			addresses: []types.Address{
+				taggedAddress("54.0.0.1", map[string]string{"slot": "0"}),
+			},
+			index:   1,
+			wantErr: true,
+		},
+		{
+			name:    "describe error",
+			err:     errors.New("access denied"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := newFakeEC2(t)
+			ec2Fake.describeAddresses = func(in *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+				requireFilter(t, in.Filters, "tag:pool", "web")
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &ec2.DescribeAddressesOutput{Addresses: tt.addresses}, nil
+			}
+
+			got, err := ResolveAddressPool(context.Background(), ec2Fake, &AddressPool{
+				TagKey:   "pool",
+				TagValue: "web",
+				SortTag:  "slot",
+				Index:    tt.index,
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("address = %q, want %q", got, tt.want)
+			}
+			ec2Fake.assertCalls([]string{"DescribeAddresses"})
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package eip
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OutputFormat selects what the CLI prints on stdout.
+type OutputFormat string
+
+const (
+	// OutputText prints nothing on stdout; results and errors are only
+	// logged on stderr.
+	OutputText OutputFormat = "text"
+	// OutputJSON also prints an OutputReport line on stdout for every bind,
+	// release, and failure.
+	OutputJSON OutputFormat = "json"
+)
+
+// ParseOutputFormat validates an -output value.
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch format := OutputFormat(value); format {
+	case OutputText, OutputJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid -output %q (want text or json)", value)
+	}
+}
+
+// OutputReport is one line of -output json: a bind result, a released
+// binding, or an error.
+type OutputReport struct {
+	Result   *BindResult   `json:"result,omitempty"`
+	Released *BindResult   `json:"released,omitempty"`
+	Error    *ErrorDetails `json:"error,omitempty"`
+}
+
+// ErrorDetails is the JSON form of an error. When the error wraps an
+// APICallError, its fields other than Err are included.
+type ErrorDetails struct {
+	Message    string   `json:"message"`
+	Operation  string   `json:"operation,omitempty"`
+	Code       string   `json:"code,omitempty"`
+	HTTPStatus int      `json:"http_status,omitempty"`
+	RequestID  string   `json:"request_id,omitempty"`
+	Resources  []string `json:"resources,omitempty"`
+}
+
+// NewErrorDetails returns the details of err.
+func NewErrorDetails(err error) *ErrorDetails {
+	details := &ErrorDetails{Message: err.Error()}
+	if apiErr, ok := errors.AsType[*APICallError](err); ok {
+		details.Operation = apiErr.Operation
+		details.Code = apiErr.Code
+		details.HTTPStatus = apiErr.HTTPStatus
+		details.RequestID = apiErr.RequestID
+		details.Resources = apiErr.Resources
+	}
+	return details
+}
@@ -0,0 +1,142 @@
+package eip
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Permission is an IAM action a configuration needs.
+type Permission struct {
+	// Action is the IAM action, e.g. "ec2:AssociateAddress".
+	Action string
+	// Reason says which step of the configuration makes the call.
+	Reason string
+	// Address reports whether the call is an Elastic IP call, made with
+	// AddressRole's credentials when it is set.
+	Address bool
+}
+
+// RequiredPermissions returns the IAM actions cfg needs, sorted by action.
+// When the target is only resolved at runtime, the permissions of both
+// address families are included.
+func RequiredPermissions(cfg *Config) []Permission {
+	var perms []Permission
+	add := func(action, reason string, address bool) {
+		for _, perm := range perms {
+			if perm.Action == action {
+				return
+			}
+		}
+		perms = append(perms, Permission{Action: action, Reason: reason, Address: address})
+	}
+
+	add("ec2:DescribeNetworkInterfaces", "find the primary ENI", false)
+
+	// Address pools only hold Elastic IPs; other runtime targets may resolve
+	// to either family.
+	deferred := cfg.TargetRef != "" && cfg.AddressPool == nil
+	ipv4 := deferred || cfg.AddressPool != nil || cfg.Family == IPFamilyIPv4
+	ipv6 := deferred || cfg.Family == IPFamilyIPv6
+	if ipv4 {
+		add("ec2:DescribeAddresses", "look up the Elastic IP", true)
+		add("ec2:AssociateAddress", "associate the Elastic IP", true)
+	}
+	if ipv6 {
+		if cfg.TargetIP == AutoIPv6 {
+			add("ec2:AssignIpv6Addresses", "assign a new IPv6 address", false)
+		} else {
+			add("ec2:DescribeSubnets", "check the target is in the ENI subnet", false)
+			add("ec2:AssignIpv6Addresses", "assign the IPv6 address or prefix", false)
+			add("ec2:UnassignIpv6Addresses", "move the IPv6 address or prefix from another ENI", false)
+		}
+		if cfg.PrimaryIPv6 == PrimaryIPv6Request {
+			add("ec2:ModifyNetworkInterfaceAttribute", "enable primary IPv6 on the ENI", false)
+		}
+	}
+
+	if cfg.TargetRef == InstanceTagTarget {
+		add("ec2:DescribeTags", "read the target from tag "+cfg.InstanceTag, false)
+	}
+	if cfg.AddressPool != nil {
+		add("ec2:DescribeAddresses", "list the Elastic IP pool", true)
+	}
+	if cfg.Instance != nil && cfg.Instance.InstanceID == "" {
+		add("ec2:DescribeInstances", "find the instance tagged "+cfg.Instance.TagKey, false)
+	}
+	if action := cfg.OnInterruption; action != nil {
+		if action.Standby == nil {
+			if ipv4 {
+				add("ec2:DisassociateAddress", "release the Elastic IP on interruption", true)
+			}
+			if ipv6 {
+				add("ec2:UnassignIpv6Addresses", "release the IPv6 address on interruption", false)
+			}
+		} else if action.Standby.InstanceID == "" {
+			add("ec2:DescribeInstances", "find the standby tagged "+action.Standby.TagKey, false)
+		}
+	}
+
+	if strings.HasPrefix(cfg.TargetRef, SSMTargetScheme) {
+		add("ssm:GetParameter", "read the target from SSM", false)
+	}
+	if strings.HasPrefix(cfg.TargetRef, SecretsManagerTargetScheme) {
+		add("secretsmanager:GetSecretValue", "read the target from Secrets Manager", false)
+	}
+	if cfg.DNS != nil {
+		add("route53:ChangeResourceRecordSets", "update DNS record "+cfg.DNS.Name, false)
+		if cfg.OnInterruption != nil && cfg.OnInterruption.Standby == nil {
+			add("route53:ListResourceRecordSets", "check DNS record "+cfg.DNS.Name+" before deleting it", false)
+		}
+	}
+	if cfg.Role != nil || cfg.AddressRole != nil {
+		add("sts:AssumeRole", "assume the configured role", false)
+	}
+
+	slices.SortFunc(perms, func(a, b Permission) int {
+		return strings.Compare(a.Action, b.Action)
+	})
+	return perms
+}
+
+// PolicyDocument is an IAM policy document.
+type PolicyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+// PolicyStatement is one statement of a PolicyDocument.
+type PolicyStatement struct {
+	Sid      string   `json:"Sid,omitempty"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+	// Condition maps condition operators to condition keys and values.
+	Condition map[string]map[string]string `json:"Condition,omitempty"`
+}
+
+// LeastPrivilegePolicy returns a policy allowing exactly the actions in perms.
+func LeastPrivilegePolicy(perms []Permission) *PolicyDocument {
+	actions := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		actions = append(actions, perm.Action)
+	}
+	return &PolicyDocument{
+		Version: "2012-10-17",
+		Statement: []PolicyStatement{{
+			Sid:      "EIPBinding",
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: []string{"*"},
+		}},
+	}
+}
+
+// JSON returns the policy as indented JSON.
+func (p *PolicyDocument) JSON() (string, error) {
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,131 @@
+package eip
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequiredPermissions(t *testing.T) {
+	role := &AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/eip-binding"}
+	tests := []struct {
+		name        string
+		cfg         Config
+		want        []string
+		wantAddress []string
+	}{
+		{
+			name:        "IPv4",
+			cfg:         Config{TargetIP: "54.162.153.80", Family: IPFamilyIPv4},
+			want:        []string{"ec2:AssociateAddress", "ec2:DescribeAddresses", "ec2:DescribeNetworkInterfaces"},
+			wantAddress: []string{"ec2:AssociateAddress", "ec2:DescribeAddresses"},
+		},
+		{
+			name: "IPv6 with primary request",
+			cfg:  Config{TargetIP: "2001:db8::1", Family: IPFamilyIPv6, PrimaryIPv6: PrimaryIPv6Request},
+			want: []string{
+				"ec2:AssignIpv6Addresses", "ec2:DescribeNetworkInterfaces", "ec2:DescribeSubnets",
+				"ec2:ModifyNetworkInterfaceAttribute", "ec2:UnassignIpv6Addresses",
+			},
+		},
+		{
+			name: "AUTO_IPV6",
+			cfg:  Config{TargetIP: AutoIPv6, Family: IPFamilyIPv6},
+			want: []string{"ec2:AssignIpv6Addresses", "ec2:DescribeNetworkInterfaces"},
+		},
+		{
+			name: "instance tag target on a tagged instance with unbind",
+			cfg: Config{
+				TargetRef:      InstanceTagTarget,
+				InstanceTag:    DefaultInstanceTag,
+				Instance:       &InstanceSelector{TagKey: "Name", TagValue: "web"},
+				OnInterruption: &InterruptionAction{},
+			},
+			want: []string{
+				"ec2:AssignIpv6Addresses", "ec2:AssociateAddress", "ec2:DescribeAddresses", "ec2:DescribeInstances",
+				"ec2:DescribeNetworkInterfaces", "ec2:DescribeSubnets", "ec2:DescribeTags",
+				"ec2:DisassociateAddress", "ec2:UnassignIpv6Addresses",
+			},
+			wantAddress: []string{"ec2:AssociateAddress", "ec2:DescribeAddresses", "ec2:DisassociateAddress"},
+		},
+		{
+			name: "address pool target with unbind",
+			cfg: Config{
+				TargetRef:      PodOrdinalTarget,
+				AddressPool:    &AddressPool{TagKey: "pool", TagValue: "web", SortTag: "order"},
+				OnInterruption: &InterruptionAction{},
+			},
+			want: []string{
+				"ec2:AssociateAddress", "ec2:DescribeAddresses", "ec2:DescribeNetworkInterfaces", "ec2:DisassociateAddress",
+			},
+			wantAddress: []string{"ec2:AssociateAddress", "ec2:DescribeAddresses", "ec2:DisassociateAddress"},
+		},
+		{
+			name: "DNS with unbind",
+			cfg: Config{
+				TargetIP:       "54.162.153.80",
+				Family:         IPFamilyIPv4,
+				DNS:            &DNSOptions{Name: "api.example.com", HostedZoneID: "Z0123456789ABC"},
+				OnInterruption: &InterruptionAction{},
+			},
+			want: []string{
+				"ec2:AssociateAddress", "ec2:DescribeAddresses", "ec2:DescribeNetworkInterfaces", "ec2:DisassociateAddress",
+				"route53:ChangeResourceRecordSets", "route53:ListResourceRecordSets",
+			},
+			wantAddress: []string{"ec2:AssociateAddress", "ec2:DescribeAddresses", "ec2:DisassociateAddress"},
+		},
+		{
+			name: "SSM target with roles",
+			cfg:  Config{TargetRef: "ssm:/eip/web", Role: role},
+			want: []string{
+				"ec2:AssignIpv6Addresses", "ec2:AssociateAddress", "ec2:DescribeAddresses",
+				"ec2:DescribeNetworkInterfaces", "ec2:DescribeSubnets", "ec2:UnassignIpv6Addresses",
+				"ssm:GetParameter", "sts:AssumeRole",
+			},
+			wantAddress: []string{"ec2:AssociateAddress", "ec2:DescribeAddresses"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, gotAddress []string
+			for _, perm := range RequiredPermissions(&tt.cfg) {
+				got = append(got, perm.Action)
+				if perm.Address {
+					gotAddress = append(gotAddress, perm.Action)
+				}
+				if perm.Reason == "" {
+					t.Errorf("%s has no reason", perm.Action)
+				}
+			}
+			requireStrings(t, got, tt.want, "actions")
+			requireStrings(t, gotAddress, tt.wantAddress, "address actions")
+		})
+	}
+}
+
+func TestLeastPrivilegePolicy(t *testing.T) {
+	policy := LeastPrivilegePolicy(RequiredPermissions(&Config{TargetIP: AutoIPv6, Family: IPFamilyIPv6}))
+	got, err := policy.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "EIPBinding",
+      "Effect": "Allow",
+      "Action": [
+        "ec2:AssignIpv6Addresses",
+        "ec2:DescribeNetworkInterfaces"
+      ],
+      "Resource": [
+        "*"
+      ]
+    }
+  ]
+}`
+	if strings.TrimSpace(got) != want {
+		t.Fatalf("policy =\n%s\nwant\n%s", got, want)
+	}
+}
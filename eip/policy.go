@@ -0,0 +1,260 @@
+package eip
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const policyUsageLine = "usage: aws-eip-binding policy -account ID [flags] -- [binding flags] <EIP>"
+
+// PolicyScope carries what ScopedPolicy needs beyond the binding Config to
+// render resource ARNs and tag conditions.
+type PolicyScope struct {
+	// Partition is the ARN partition, e.g. "aws" or "aws-cn".
+	Partition string
+	// Region scopes ARNs to one region. Empty matches any region.
+	Region string
+	// Account owns the network interfaces and instances.
+	Account string
+	// AddressAccount owns the Elastic IPs. It defaults to Account.
+	AddressAccount string
+	// AllocationIDs are the Elastic IPs an IPv4 target may associate. Empty
+	// allows any Elastic IP in AddressAccount, narrowed by pool tags if any.
+	AllocationIDs []string
+	// NetworkInterfaceTags restricts network interface actions to ENIs
+	// carrying all of these tags.
+	NetworkInterfaceTags map[string]string
+	// Address renders the policy for Config.AddressRole (Elastic IP calls
+	// only) instead of the one for the default credentials or Config.Role.
+	Address bool
+}
+
+// ParsePolicyConfig parses the policy command's flags, followed by "--" and
+// the binding flags and target, which are parsed with ParseConfig.
+func ParsePolicyConfig(args []string, getenv func(string) string) (*PolicyScope, *Config, error) {
+	fs := flag.NewFlagSet("aws-eip-binding policy", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	scope := &PolicyScope{NetworkInterfaceTags: map[string]string{}}
+	fs.StringVar(&scope.Partition, "partition", "aws", "ARN partition")
+	fs.StringVar(&scope.Region, "region", getenv("AWS_REGION"), "region of the resources (default $AWS_REGION, or any region)")
+	fs.StringVar(&scope.Account, "account", "", "account ID owning the instances and network interfaces")
+	fs.StringVar(&scope.AddressAccount, "address-account", "", "account ID owning the Elastic IPs (default -account)")
+	fs.Func("allocation-id", "Elastic IP allocation the target may use (repeatable)", func(value string) error {
+		if !strings.HasPrefix(value, "eipalloc-") {
+			return fmt.Errorf("%q is not an allocation ID", value)
+		}
+		scope.AllocationIDs = append(scope.AllocationIDs, value)
+		return nil
+	})
+	fs.Func("eni-tag", "KEY=VALUE tag the bound network interfaces carry (repeatable)", func(value string) error {
+		key, tagValue, ok := strings.Cut(value, "=")
+		if !ok || key == "" || tagValue == "" {
+			return fmt.Errorf("%q is not KEY=VALUE", value)
+		}
+		scope.NetworkInterfaceTags[key] = tagValue
+		return nil
+	})
+	fs.BoolVar(&scope.Address, "address-policy", false, "render the policy for -address-role-arn instead")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, nil, policyUsageError(fs)
+		}
+		return nil, nil, fmt.Errorf("%w\n%s", err, policyUsageError(fs))
+	}
+	if !isAccountID(scope.Account) {
+		return nil, nil, fmt.Errorf("policy: -account must be a 12-digit account ID\n%s", policyUsageError(fs))
+	}
+	if scope.AddressAccount == "" {
+		scope.AddressAccount = scope.Account
+	} else if !isAccountID(scope.AddressAccount) {
+		return nil, nil, errors.New("policy: -address-account must be a 12-digit account ID")
+	}
+
+	cfg, err := ParseConfig(fs.Args(), getenv)
+	if err != nil {
+		return nil, nil, err
+	}
+	if scope.Address && cfg.AddressRole == nil {
+		return nil, nil, errors.New("policy: -address-policy requires -address-role-arn")
+	}
+	return scope, cfg, nil
+}
+
+func isAccountID(value string) bool {
+	return len(value) == 12 && !strings.ContainsFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+}
+
+func policyUsageError(fs *flag.FlagSet) error {
+	var b strings.Builder
+	b.WriteString(policyUsageLine)
+	b.WriteString("\n\nflags:\n")
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+	return errors.New(strings.TrimRight(b.String(), "\n"))
+}
+
+// Actions authorized against elastic-ip and network-interface resources. All
+// other EC2 actions the binder uses only support "Resource": "*".
+var (
+	elasticIPActions        = []string{"ec2:AssociateAddress", "ec2:DisassociateAddress"}
+	networkInterfaceActions = []string{"ec2:AssignIpv6Addresses", "ec2:AssociateAddress", "ec2:ModifyNetworkInterfaceAttribute", "ec2:UnassignIpv6Addresses"}
+)
+
+// ScopedPolicy returns the policy cfg needs with Elastic IP and network
+// interface actions scoped to ARNs in scope, and to aws:ResourceTag
+// conditions for address pool and ENI tags. When cfg has an AddressRole,
+// scope.Address selects which of the two principals' policy is rendered.
+func ScopedPolicy(cfg *Config, scope *PolicyScope) *PolicyDocument {
+	var describe, elasticIP, networkInterface, readTarget, dns, dnsRead, assumeRole []string
+	for _, perm := range RequiredPermissions(cfg) {
+		if cfg.AddressRole != nil && perm.Address != scope.Address {
+			continue
+		}
+		scoped := false
+		if slices.Contains(elasticIPActions, perm.Action) {
+			elasticIP = append(elasticIP, perm.Action)
+			scoped = true
+		}
+		if slices.Contains(networkInterfaceActions, perm.Action) {
+			networkInterface = append(networkInterface, perm.Action)
+			scoped = true
+		}
+		switch {
+		case scoped:
+		case strings.HasPrefix(perm.Action, "ec2:"):
+			describe = append(describe, perm.Action)
+		case perm.Action == "route53:ListResourceRecordSets":
+			dnsRead = append(dnsRead, perm.Action)
+		case strings.HasPrefix(perm.Action, "route53:"):
+			dns = append(dns, perm.Action)
+		case perm.Action == "sts:AssumeRole":
+			assumeRole = append(assumeRole, perm.Action)
+		default:
+			readTarget = append(readTarget, perm.Action)
+		}
+	}
+
+	policy := &PolicyDocument{Version: "2012-10-17"}
+	if len(describe) > 0 {
+		policy.Statement = append(policy.Statement, PolicyStatement{
+			Sid:      "Describe",
+			Effect:   "Allow",
+			Action:   describe,
+			Resource: []string{"*"},
+		})
+	}
+	if len(elasticIP) > 0 {
+		statement := PolicyStatement{
+			Sid:      "ElasticIP",
+			Effect:   "Allow",
+			Action:   elasticIP,
+			Resource: []string{scope.arn("ec2", scope.AddressAccount, "elastic-ip/*")},
+		}
+		if len(scope.AllocationIDs) > 0 {
+			statement.Resource = nil
+			for _, id := range scope.AllocationIDs {
+				statement.Resource = append(statement.Resource, scope.arn("ec2", scope.AddressAccount, "elastic-ip/"+id))
+			}
+		}
+		if cfg.AddressPool != nil {
+			statement.Condition = resourceTagCondition(map[string]string{cfg.AddressPool.TagKey: cfg.AddressPool.TagValue})
+		}
+		policy.Statement = append(policy.Statement, statement)
+	}
+	if len(networkInterface) > 0 {
+		policy.Statement = append(policy.Statement, PolicyStatement{
+			Sid:       "NetworkInterface",
+			Effect:    "Allow",
+			Action:    networkInterface,
+			Resource:  []string{scope.arn("ec2", scope.Account, "network-interface/*")},
+			Condition: resourceTagCondition(scope.NetworkInterfaceTags),
+		})
+	}
+	if len(readTarget) > 0 {
+		policy.Statement = append(policy.Statement, PolicyStatement{
+			Sid:      "ReadTarget",
+			Effect:   "Allow",
+			Action:   readTarget,
+			Resource: []string{scope.targetARN(cfg.TargetRef)},
+		})
+	}
+	if len(dns) > 0 {
+		// Route 53 hosted zone ARNs have no region or account.
+		policy.Statement = append(policy.Statement, PolicyStatement{
+			Sid:      "DNS",
+			Effect:   "Allow",
+			Action:   dns,
+			Resource: []string{fmt.Sprintf("arn:%s:route53:::hostedzone/%s", scope.Partition, cfg.DNS.HostedZoneID)},
+			Condition: map[string]map[string]string{"ForAllValues:StringEquals": {
+				"route53:ChangeResourceRecordSetsNormalizedRecordNames": normalizedRecordName(cfg.DNS.Name),
+			}},
+		})
+	}
+	if len(dnsRead) > 0 {
+		// Listing record sets has no record name condition key.
+		policy.Statement = append(policy.Statement, PolicyStatement{
+			Sid:      "DNSRead",
+			Effect:   "Allow",
+			Action:   dnsRead,
+			Resource: []string{fmt.Sprintf("arn:%s:route53:::hostedzone/%s", scope.Partition, cfg.DNS.HostedZoneID)},
+		})
+	}
+	if len(assumeRole) > 0 {
+		var roles []string
+		for _, role := range []*AssumeRole{cfg.Role, cfg.AddressRole} {
+			if role != nil && !slices.Contains(roles, role.RoleARN) {
+				roles = append(roles, role.RoleARN)
+			}
+		}
+		policy.Statement = append(policy.Statement, PolicyStatement{
+			Sid:      "AssumeRole",
+			Effect:   "Allow",
+			Action:   assumeRole,
+			Resource: roles,
+		})
+	}
+	return policy
+}
+
+func (s *PolicyScope) arn(service, account, resource string) string {
+	region := s.Region
+	if region == "" {
+		region = "*"
+	}
+	return fmt.Sprintf("arn:%s:%s:%s:%s:%s", s.Partition, service, region, account, resource)
+}
+
+// targetARN returns the ARN of the SSM parameter or secret ref names.
+func (s *PolicyScope) targetARN(ref string) string {
+	if name, ok := strings.CutPrefix(ref, SSMTargetScheme); ok {
+		return s.arn("ssm", s.Account, "parameter/"+strings.TrimPrefix(name, "/"))
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(ref, SecretsManagerTargetScheme), "#")
+	if strings.HasPrefix(name, "arn:") {
+		return name
+	}
+	// Secrets Manager appends a random six-character suffix to secret ARNs.
+	return s.arn("secretsmanager", s.Account, "secret:"+name+"-??????")
+}
+
+// normalizedRecordName returns name the way Route 53 condition keys compare
+// it: lower case without the trailing dot.
+func normalizedRecordName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+func resourceTagCondition(tags map[string]string) map[string]map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	equals := make(map[string]string, len(tags))
+	for key, value := range tags {
+		equals["aws:ResourceTag/"+key] = value
+	}
+	return map[string]map[string]string{"StringEquals": equals}
+}
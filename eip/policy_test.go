@@ -0,0 +1,99 @@
+package eip
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestScopedPolicyGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{
+			name: "ipv4-allocation",
+			args: []string{"-account", "111111111111", "-region", "us-east-1", "-allocation-id", "eipalloc-0123456789abcdef0",
+				"-eni-tag", "eip-binding=enabled", "--", "-on-interruption", "unbind", "54.162.153.80"},
+		},
+		{
+			name: "ipv6-any-region",
+			args: []string{"-account", "111111111111", "--", "-ipv6-primary", "request", "2001:db8::1"},
+		},
+		{
+			name: "address-pool",
+			args: []string{"-account", "111111111111", "-region", "eu-west-1", "--",
+				"-ordinal-addresses", "tag:pool=web", "-ordinal-sort-tag", "order", PodOrdinalTarget},
+			env: map[string]string{"POD_NAME": "web-2"},
+		},
+		{
+			name: "ssm-role",
+			args: []string{"-account", "111111111111", "-region", "us-east-1", "--",
+				"-role-arn", "arn:aws:iam::111111111111:role/eip-binding", "-instance", "tag:Name=web", "ssm:/eip/web"},
+		},
+		{
+			name: "address-role",
+			args: []string{"-account", "111111111111", "-address-account", "222222222222", "-region", "us-east-1", "-address-policy", "--",
+				"-address-role-arn", "arn:aws:iam::222222222222:role/eip-addresses", "54.162.153.80"},
+		},
+		{
+			name: "dns",
+			args: []string{"-account", "111111111111", "-region", "us-east-1", "--",
+				"-dns-name", "API.example.com.", "-dns-zone-id", "Z0123456789ABC", "-on-interruption", "unbind", "54.162.153.80"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, cfg, err := ParsePolicyConfig(tt.args, func(key string) string { return tt.env[key] })
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := ScopedPolicy(cfg, scope).JSON()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got += "\n"
+
+			path := filepath.Join("testdata", "policies", tt.name+".json")
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatalf("write golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Fatalf("policy differs from %s (rerun with -update to accept):\n%s", path, got)
+			}
+		})
+	}
+}
+
+func TestParsePolicyConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing account", args: []string{"--", "54.162.153.80"}},
+		{name: "short account", args: []string{"-account", "1234", "--", "54.162.153.80"}},
+		{name: "bad allocation ID", args: []string{"-account", "111111111111", "-allocation-id", "54.162.153.80", "--", "54.162.153.80"}},
+		{name: "bad ENI tag", args: []string{"-account", "111111111111", "-eni-tag", "enabled", "--", "54.162.153.80"}},
+		{name: "address policy without address role", args: []string{"-account", "111111111111", "-address-policy", "--", "54.162.153.80"}},
+		{name: "missing target", args: []string{"-account", "111111111111"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParsePolicyConfig(tt.args, func(string) string { return "" }); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
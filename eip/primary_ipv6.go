@@ -0,0 +1,136 @@
+package eip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// PrimaryIPv6Mode controls how Bind treats the primary IPv6 designation of
+// a bound IPv6 address.
+type PrimaryIPv6Mode string
+
+const (
+	// PrimaryIPv6Ignore reports the primary flag when it is already known but
+	// never checks it.
+	PrimaryIPv6Ignore PrimaryIPv6Mode = ""
+	// PrimaryIPv6Verify fails the bind when the address is not the ENI's
+	// primary IPv6 address. An address that is not on the ENI yet is refused
+	// before it moves, since it could not be primary there.
+	PrimaryIPv6Verify PrimaryIPv6Mode = "verify"
+	// PrimaryIPv6Request enables primary IPv6 on the ENI when it has none yet,
+	// then verifies the bound address became primary. EC2 makes the first IPv6
+	// GUA on the ENI primary and never changes it afterwards, so this only
+	// succeeds when the target is the ENI's first IPv6 address; an ENI that
+	// already holds another IPv6 address is refused before the move.
+	PrimaryIPv6Request PrimaryIPv6Mode = "request"
+)
+
+// ParsePrimaryIPv6Mode validates a primary IPv6 mode name.
+func ParsePrimaryIPv6Mode(value string) (PrimaryIPv6Mode, error) {
+	switch mode := PrimaryIPv6Mode(value); mode {
+	case PrimaryIPv6Ignore, PrimaryIPv6Verify, PrimaryIPv6Request:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid primary IPv6 mode %q (want %q or %q)", value, PrimaryIPv6Verify, PrimaryIPv6Request)
+	}
+}
+
+// checkPrimaryIPv6 records whether result.TargetIP is primary on eni and
+// enforces b.PrimaryIPv6.
+func (b *Binder) checkPrimaryIPv6(ctx context.Context, result *BindResult, eni *types.NetworkInterface) error {
+	result.PrimaryIPv6 = isPrimaryIPv6(eni, result.TargetIP)
+	if b.PrimaryIPv6 == PrimaryIPv6Ignore || result.PrimaryIPv6 {
+		return nil
+	}
+
+	if b.PrimaryIPv6 == PrimaryIPv6Request && primaryIPv6(eni) == "" {
+		b.Logger.Printf("Enabling primary IPv6 on ENI %s", result.NetworkInterfaceID)
+		_, err := b.EC2.ModifyNetworkInterfaceAttribute(ctx, &ec2.ModifyNetworkInterfaceAttributeInput{
+			NetworkInterfaceId: new(result.NetworkInterfaceID),
+			EnablePrimaryIpv6:  new(true),
+		})
+		if err != nil {
+			return fmt.Errorf("enable primary IPv6 on ENI %s: %w", result.NetworkInterfaceID,
+				newAPICallError("ModifyNetworkInterfaceAttribute", err, result.NetworkInterfaceID))
+		}
+
+		eni, err = b.describeNetworkInterface(ctx, result.NetworkInterfaceID)
+		if err != nil {
+			return err
+		}
+		result.PrimaryIPv6 = isPrimaryIPv6(eni, result.TargetIP)
+		if result.PrimaryIPv6 {
+			b.Logger.Printf("IPv6 %s is now the primary IPv6 of ENI %s", result.TargetIP, result.NetworkInterfaceID)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("IPv6 %s on ENI %s is not the primary IPv6 address", result.TargetIP, result.NetworkInterfaceID)
+}
+
+// precheckPrimaryIPv6 fails before targetIP is moved onto eni when it could
+// not end up primary there. EC2 never replaces an ENI's primary IPv6 address,
+// and enabling primary IPv6 picks the ENI's first IPv6 address, so verify
+// needs targetIP to already be primary and request needs targetIP to be the
+// only address. An empty targetIP stands for an address EC2 has yet to pick.
+func (b *Binder) precheckPrimaryIPv6(eni *types.NetworkInterface, targetIP string) error {
+	networkInterfaceID := aws.ToString(eni.NetworkInterfaceId)
+	target := "IPv6 " + targetIP
+	if targetIP == "" {
+		target = "a new IPv6 address"
+	}
+	switch b.PrimaryIPv6 {
+	case PrimaryIPv6Verify:
+		primary := primaryIPv6(eni)
+		if primary == "" {
+			return fmt.Errorf("%s cannot be verified as primary on ENI %s, which has no primary IPv6 address", target, networkInterfaceID)
+		}
+		if primary != targetIP {
+			return fmt.Errorf("%s cannot become primary on ENI %s, which already has primary IPv6 %s", target, networkInterfaceID, primary)
+		}
+	case PrimaryIPv6Request:
+		for _, ipv6 := range eni.Ipv6Addresses {
+			if address := aws.ToString(ipv6.Ipv6Address); address != "" && address != targetIP {
+				return fmt.Errorf("%s cannot become primary on ENI %s, which already has IPv6 %s", target, networkInterfaceID, address)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Binder) describeNetworkInterface(ctx context.Context, networkInterfaceID string) (*types.NetworkInterface, error) {
+	eniOut, err := b.EC2.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
+		NetworkInterfaceIds: []string{networkInterfaceID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describe network interface %s: %w", networkInterfaceID,
+			newAPICallError("DescribeNetworkInterfaces", err, networkInterfaceID))
+	}
+	if len(eniOut.NetworkInterfaces) == 0 {
+		return nil, fmt.Errorf("network interface %s not found", networkInterfaceID)
+	}
+	return &eniOut.NetworkInterfaces[0], nil
+}
+
+func isPrimaryIPv6(eni *types.NetworkInterface, targetIP string) bool {
+	for _, ipv6 := range eni.Ipv6Addresses {
+		if ipv6.Ipv6Address != nil && *ipv6.Ipv6Address == targetIP {
+			return ipv6.IsPrimaryIpv6 != nil && *ipv6.IsPrimaryIpv6
+		}
+	}
+	return false
+}
+
+// primaryIPv6 returns the primary IPv6 address of eni, or "" when it has none.
+func primaryIPv6(eni *types.NetworkInterface) string {
+	for _, ipv6 := range eni.Ipv6Addresses {
+		if ipv6.IsPrimaryIpv6 != nil && *ipv6.IsPrimaryIpv6 {
+			return aws.ToString(ipv6.Ipv6Address)
+		}
+	}
+	return ""
+}
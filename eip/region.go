@@ -0,0 +1,32 @@
+package eip
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Region sources reported by ResolveRegion.
+const (
+	RegionSourceConfig   = "AWS config"
+	RegionSourceMetadata = "instance metadata"
+)
+
+// ResolveRegion returns configured when it is set, and otherwise the region
+// from instance metadata ("placement/region"). It fails when neither is
+// available so EC2 calls are not attempted without a region. The returned
+// source is RegionSourceConfig or RegionSourceMetadata.
+func ResolveRegion(ctx context.Context, configured string, imds MetadataClient) (region, source string, err error) {
+	if configured != "" {
+		return configured, RegionSourceConfig, nil
+	}
+	region, err = readMetadata(ctx, imds, "placement/region")
+	if err != nil {
+		return "", "", fmt.Errorf("no AWS region configured (set AWS_REGION) and instance metadata is unavailable: %w", err)
+	}
+	region = strings.TrimSpace(region)
+	if region == "" {
+		return "", "", fmt.Errorf("no AWS region configured (set AWS_REGION) and instance metadata returned an empty region")
+	}
+	return region, RegionSourceMetadata, nil
+}
@@ -0,0 +1,66 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResolveRegion(t *testing.T) {
+	tests := []struct {
+		name        string
+		configured  string
+		metadata    map[string]string
+		metadataErr map[string]error
+		want        string
+		wantSource  string
+		wantErr     bool
+		wantCalls   []string
+	}{
+		{
+			name:       "configured region wins",
+			configured: "eu-west-1",
+			want:       "eu-west-1",
+			wantSource: RegionSourceConfig,
+		},
+		{
+			name:       "falls back to instance metadata",
+			metadata:   map[string]string{"placement/region": "us-east-1\n"},
+			want:       "us-east-1",
+			wantSource: RegionSourceMetadata,
+			wantCalls:  []string{"GetMetadata:placement/region"},
+		},
+		{
+			name:        "no region anywhere",
+			metadataErr: map[string]error{"placement/region": errors.New("connection refused")},
+			wantErr:     true,
+			wantCalls:   []string{"GetMetadata:placement/region"},
+		},
+		{
+			name:      "empty metadata region",
+			metadata:  map[string]string{"placement/region": " "},
+			wantErr:   true,
+			wantCalls: []string{"GetMetadata:placement/region"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imdsFake := newFakeIMDS(t, tt.metadata)
+			imdsFake.metadataErr = tt.metadataErr
+
+			got, source, err := ResolveRegion(context.Background(), tt.configured, imdsFake)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want || source != tt.wantSource {
+				t.Fatalf("region = %q from %q, want %q from %q", got, source, tt.want, tt.wantSource)
+			}
+			imdsFake.assertCalls(tt.wantCalls)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package eip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// Remote target schemes. "ssm:/path/to/param" reads an SSM parameter and
+// "secretsmanager:name#key" reads a Secrets Manager secret, optionally picking
+// one key of a JSON secret.
+const (
+	SSMTargetScheme            = "ssm:"
+	SecretsManagerTargetScheme = "secretsmanager:"
+)
+
+// SSMAPI is the subset of the SSM client used to resolve targets.
+type SSMAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// SecretsManagerAPI is the subset of the Secrets Manager client used to
+// resolve targets.
+type SecretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// TargetResolver fetches the target named by a Config.TargetRef.
+type TargetResolver interface {
+	ResolveTarget(ctx context.Context, ref string) (string, error)
+}
+
+// IsRemoteTarget reports whether target names an SSM parameter or Secrets
+// Manager secret.
+func IsRemoteTarget(target string) bool {
+	return strings.HasPrefix(target, SSMTargetScheme) || strings.HasPrefix(target, SecretsManagerTargetScheme)
+}
+
+// RemoteTargetResolver resolves ssm: and secretsmanager: targets.
+type RemoteTargetResolver struct {
+	SSM            SSMAPI
+	SecretsManager SecretsManagerAPI
+}
+
+// NewRemoteTargetResolver creates a RemoteTargetResolver.
+func NewRemoteTargetResolver(ssmClient SSMAPI, secretsClient SecretsManagerAPI) *RemoteTargetResolver {
+	return &RemoteTargetResolver{SSM: ssmClient, SecretsManager: secretsClient}
+}
+
+// ResolveTarget returns the value stored under ref, trimmed of surrounding
+// whitespace. It does not validate the value; pass it to Config.SetTarget.
+func (r *RemoteTargetResolver) ResolveTarget(ctx context.Context, ref string) (string, error) {
+	var (
+		value string
+		err   error
+	)
+	if name, ok := strings.CutPrefix(ref, SSMTargetScheme); ok {
+		value, err = r.getParameter(ctx, name)
+	} else if name, ok := strings.CutPrefix(ref, SecretsManagerTargetScheme); ok {
+		value, err = r.getSecret(ctx, name)
+	} else {
+		return "", fmt.Errorf("unsupported target reference: %s", ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", ref)
+	}
+	return value, nil
+}
+
+func (r *RemoteTargetResolver) getParameter(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("%s target requires a parameter name", SSMTargetScheme)
+	}
+	out, err := r.SSM.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("get SSM parameter %s: %w", name, newAPICallError("GetParameter", err, name))
+	}
+	if out.Parameter == nil || out.Parameter.Value == nil {
+		return "", fmt.Errorf("SSM parameter %s has no value", name)
+	}
+	return *out.Parameter.Value, nil
+}
+
+func (r *RemoteTargetResolver) getSecret(ctx context.Context, ref string) (string, error) {
+	name, key, hasKey := strings.Cut(ref, "#")
+	if name == "" || (hasKey && key == "") {
+		return "", fmt.Errorf("%s target must be NAME or NAME#KEY", SecretsManagerTargetScheme)
+	}
+	out, err := r.SecretsManager.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("get secret %s: %w", name, newAPICallError("GetSecretValue", err, name))
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", name)
+	}
+	if !hasKey {
+		return *out.SecretString, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(*out.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", name, err)
+	}
+	value, ok := fields[key].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s has no string key %q", name, key)
+	}
+	return value, nil
+}
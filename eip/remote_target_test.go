@@ -0,0 +1,111 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+type fakeParameterStore map[string]string
+
+func (f fakeParameterStore) GetParameter(_ context.Context, in *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	if in.WithDecryption == nil || !*in.WithDecryption {
+		return nil, errors.New("WithDecryption not set")
+	}
+	value, ok := f[*in.Name]
+	if !ok {
+		return nil, errors.New("ParameterNotFound")
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssmtypes.Parameter{Name: in.Name, Value: new(value)}}, nil
+}
+
+type fakeSecretStore map[string]string
+
+func (f fakeSecretStore) GetSecretValue(_ context.Context, in *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	value, ok := f[*in.SecretId]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return &secretsmanager.GetSecretValueOutput{Name: in.SecretId, SecretString: new(value)}, nil
+}
+
+func TestRemoteTargetResolver(t *testing.T) {
+	resolver := NewRemoteTargetResolver(
+		fakeParameterStore{
+			"/prod/web/eip":   " 54.162.153.80\n",
+			"/prod/web/empty": "  ",
+		},
+		fakeSecretStore{
+			"prod/eips":  `{"web-0": "2001:db8::1", "count": 2}`,
+			"prod/plain": "54.162.153.81",
+		},
+	)
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "ssm:/prod/web/eip", want: "54.162.153.80"},
+		{ref: "ssm:/prod/web/missing", wantErr: true},
+		{ref: "ssm:/prod/web/empty", wantErr: true},
+		{ref: "ssm:", wantErr: true},
+		{ref: "secretsmanager:prod/eips#web-0", want: "2001:db8::1"},
+		{ref: "secretsmanager:prod/plain", want: "54.162.153.81"},
+		{ref: "secretsmanager:prod/eips#web-1", wantErr: true},
+		{ref: "secretsmanager:prod/eips#count", wantErr: true},
+		{ref: "secretsmanager:prod/plain#web-0", wantErr: true},
+		{ref: "secretsmanager:prod/eips#", wantErr: true},
+		{ref: "secretsmanager:prod/missing", wantErr: true},
+		{ref: "POD_ANNOTATION", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := resolver.ResolveTarget(context.Background(), tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("target = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteTargetResolverWrapsAPIErrors(t *testing.T) {
+	resolver := NewRemoteTargetResolver(fakeParameterStore{}, fakeSecretStore{})
+
+	tests := []struct {
+		ref           string
+		wantOperation string
+		wantResource  string
+	}{
+		{ref: "ssm:/prod/web/missing", wantOperation: "GetParameter", wantResource: "/prod/web/missing"},
+		{ref: "secretsmanager:prod/missing#web-0", wantOperation: "GetSecretValue", wantResource: "prod/missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			_, err := resolver.ResolveTarget(context.Background(), tt.ref)
+			apiErr, ok := errors.AsType[*APICallError](err)
+			if !ok {
+				t.Fatalf("error = %v, want an APICallError", err)
+			}
+			if apiErr.Operation != tt.wantOperation {
+				t.Fatalf("operation = %q, want %q", apiErr.Operation, tt.wantOperation)
+			}
+			requireStrings(t, apiErr.Resources, []string{tt.wantResource}, "resources")
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package eip
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// defaultRoleSessionName is used when no instance ID is known to derive the
+// session name from.
+const defaultRoleSessionName = "aws-eip-binding"
+
+// AssumeRole names an IAM role to assume before calling EC2, for example to
+// manage addresses in a networking account from a workload account.
+type AssumeRole struct {
+	RoleARN    string
+	ExternalID string
+	// SessionName overrides the session name derived from the instance ID.
+	SessionName string
+}
+
+// parseRoleARN checks that value is an IAM role ARN.
+func parseRoleARN(flagName, value string) error {
+	parsed, err := arn.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", flagName, value, err)
+	}
+	if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return fmt.Errorf("invalid %s %q: not an IAM role ARN", flagName, value)
+	}
+	return nil
+}
+
+// SessionNameFor returns the role session name to use on instanceID, so
+// CloudTrail shows which instance moved an address. An explicit SessionName
+// wins; without an instance ID the name is "aws-eip-binding".
+func (r *AssumeRole) SessionNameFor(instanceID string) string {
+	if r.SessionName != "" {
+		return r.SessionName
+	}
+	if instanceID == "" {
+		return defaultRoleSessionName
+	}
+	return defaultRoleSessionName + "-" + instanceID
+}
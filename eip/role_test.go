@@ -0,0 +1,17 @@
+package eip
+
+import "testing"
+
+func TestAssumeRoleSessionNameFor(t *testing.T) {
+	role := &AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/eip-binding"}
+	if got := role.SessionNameFor("i-0123456789abcdef0"); got != "aws-eip-binding-i-0123456789abcdef0" {
+		t.Errorf("session name = %q", got)
+	}
+	if got := role.SessionNameFor(""); got != "aws-eip-binding" {
+		t.Errorf("session name without instance = %q", got)
+	}
+	role.SessionName = "failover"
+	if got := role.SessionNameFor("i-0123456789abcdef0"); got != "failover" {
+		t.Errorf("explicit session name = %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// Route53API is the subset of the Route 53 client used by Route53Provider.
+type Route53API interface {
+	ChangeResourceRecordSets(ctx context.Context, params *route53.ChangeResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error)
+	ListResourceRecordSets(ctx context.Context, params *route53.ListResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error)
+}
+
+// Route53Provider is a DNSProvider for one Route 53 hosted zone.
+type Route53Provider struct {
+	Client       Route53API
+	HostedZoneID string
+}
+
+// NewRoute53Provider creates a Route53Provider for hostedZoneID.
+func NewRoute53Provider(client Route53API, hostedZoneID string) *Route53Provider {
+	return &Route53Provider{Client: client, HostedZoneID: hostedZoneID}
+}
+
+func (p *Route53Provider) UpsertRecord(ctx context.Context, record DNSRecord) error {
+	return p.change(ctx, route53types.ChangeActionUpsert, record)
+}
+
+// DeleteRecord reads the record set and deletes it only while it holds
+// exactly record's value. The TTL is taken from the record set, so a record
+// published with another TTL is still removed. When the delete is rejected,
+// the record set is read again; one that is gone or changed in the meantime
+// counts as deleted or taken over.
+func (p *Route53Provider) DeleteRecord(ctx context.Context, record DNSRecord) error {
+	ttl, held, err := p.heldRecord(ctx, record)
+	if err != nil || !held {
+		return err
+	}
+	record.TTL = ttl
+	err = p.change(ctx, route53types.ChangeActionDelete, record)
+	if _, ok := errors.AsType[*route53types.InvalidChangeBatch](err); ok {
+		if _, held, readErr := p.heldRecord(ctx, record); readErr == nil && !held {
+			return nil
+		}
+	}
+	return err
+}
+
+// heldRecord reports whether the record set with record's name and type
+// holds exactly record's value, and returns its TTL. A missing hosted zone
+// holds nothing.
+func (p *Route53Provider) heldRecord(ctx context.Context, record DNSRecord) (ttl int64, held bool, err error) {
+	out, err := p.Client.ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    new(p.HostedZoneID),
+		StartRecordName: new(record.Name),
+		StartRecordType: route53types.RRType(record.Type),
+		MaxItems:        new(int32(1)),
+	})
+	if _, ok := errors.AsType[*route53types.NoSuchHostedZone](err); ok {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, newAPICallError("ListResourceRecordSets", err, p.HostedZoneID, record.Name)
+	}
+	// The listing starts at record's name and type, so the first set is
+	// either record's set or the one after it.
+	if len(out.ResourceRecordSets) == 0 {
+		return 0, false, nil
+	}
+	set := out.ResourceRecordSets[0]
+	if !sameRecordName(aws.ToString(set.Name), record.Name) || string(set.Type) != record.Type || set.SetIdentifier != nil {
+		return 0, false, nil
+	}
+	if len(set.ResourceRecords) != 1 || !sameAddress(aws.ToString(set.ResourceRecords[0].Value), record.Value) {
+		return 0, false, nil
+	}
+	return aws.ToInt64(set.TTL), true, nil
+}
+
+// sameRecordName compares DNS names case-insensitively, ignoring the
+// trailing dot Route 53 returns.
+func sameRecordName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+// sameAddress compares two IP addresses in any textual form.
+func sameAddress(a, b string) bool {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return addrA == addrB
+}
+
+func (p *Route53Provider) change(ctx context.Context, action route53types.ChangeAction, record DNSRecord) error {
+	_, err := p.Client.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: new(p.HostedZoneID),
+		ChangeBatch: &route53types.ChangeBatch{
+			Comment: new("aws-eip-binding"),
+			Changes: []route53types.Change{{
+				Action: action,
+				ResourceRecordSet: &route53types.ResourceRecordSet{
+					Name:            new(record.Name),
+					Type:            route53types.RRType(record.Type),
+					TTL:             new(record.TTL),
+					ResourceRecords: []route53types.ResourceRecord{{Value: new(record.Value)}},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		return newAPICallError("ChangeResourceRecordSets", err, p.HostedZoneID, record.Name)
+	}
+	return nil
+}
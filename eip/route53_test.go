@@ -0,0 +1,159 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+type fakeRoute53 struct {
+	inputs    []*route53.ChangeResourceRecordSetsInput
+	err       error
+	requestID string
+	// listings answer ListResourceRecordSets calls in order; later calls
+	// list nothing.
+	listings [][]route53types.ResourceRecordSet
+	listErr  error
+	lists    int
+}
+
+func (f *fakeRoute53) ChangeResourceRecordSets(_ context.Context, in *route53.ChangeResourceRecordSetsInput, _ ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error) {
+	f.inputs = append(f.inputs, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := &route53.ChangeResourceRecordSetsOutput{}
+	awsmiddleware.SetRequestIDMetadata(&out.ResultMetadata, f.requestID)
+	return out, nil
+}
+
+func (f *fakeRoute53) ListResourceRecordSets(_ context.Context, _ *route53.ListResourceRecordSetsInput, _ ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error) {
+	f.lists++
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	out := &route53.ListResourceRecordSetsOutput{}
+	awsmiddleware.SetRequestIDMetadata(&out.ResultMetadata, f.requestID)
+	if f.lists <= len(f.listings) {
+		out.ResourceRecordSets = f.listings[f.lists-1]
+	}
+	return out, nil
+}
+
+func recordSet(name string, rrType route53types.RRType, ttl int64, values ...string) []route53types.ResourceRecordSet {
+	set := route53types.ResourceRecordSet{Name: new(name), Type: rrType, TTL: new(ttl)}
+	for _, value := range values {
+		set.ResourceRecords = append(set.ResourceRecords, route53types.ResourceRecord{Value: new(value)})
+	}
+	return []route53types.ResourceRecordSet{set}
+}
+
+func TestRoute53ProviderUpsertRecord(t *testing.T) {
+	client := &fakeRoute53{}
+	provider := NewRoute53Provider(client, "Z0123456789ABC")
+	record := DNSRecord{Name: "api.example.com", Type: "AAAA", Value: "2001:db8::1", TTL: 30}
+	if err := provider.UpsertRecord(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.inputs) != 1 {
+		t.Fatalf("ChangeResourceRecordSets calls = %d, want 1", len(client.inputs))
+	}
+	in := client.inputs[0]
+	requireStringPtr(t, in.HostedZoneId, "Z0123456789ABC", "HostedZoneId")
+	if len(in.ChangeBatch.Changes) != 1 {
+		t.Fatalf("changes = %d, want 1", len(in.ChangeBatch.Changes))
+	}
+	change := in.ChangeBatch.Changes[0]
+	if change.Action != route53types.ChangeActionUpsert {
+		t.Fatalf("Action = %q, want UPSERT", change.Action)
+	}
+	set := change.ResourceRecordSet
+	requireStringPtr(t, set.Name, "api.example.com", "Name")
+	if set.Type != route53types.RRTypeAaaa {
+		t.Fatalf("Type = %q, want AAAA", set.Type)
+	}
+	if set.TTL == nil || *set.TTL != 30 {
+		t.Fatalf("TTL = %v, want 30", set.TTL)
+	}
+	if len(set.ResourceRecords) != 1 {
+		t.Fatalf("ResourceRecords = %d, want 1", len(set.ResourceRecords))
+	}
+	requireStringPtr(t, set.ResourceRecords[0].Value, "2001:db8::1", "Value")
+}
+
+func TestRoute53ProviderDeleteRecord(t *testing.T) {
+	record := DNSRecord{Name: "api.example.com", Type: "A", Value: "54.162.153.80", TTL: 60}
+	held := recordSet("api.example.com.", route53types.RRTypeA, 300, "54.162.153.80")
+	invalid := &route53types.InvalidChangeBatch{Message: new("rejected")}
+	tests := []struct {
+		name       string
+		listings   [][]route53types.ResourceRecordSet
+		listErr    error
+		err        error
+		wantDelete bool
+		wantErrOp  string
+	}{
+		{name: "deleted with the TTL read back", listings: [][]route53types.ResourceRecordSet{held}, wantDelete: true},
+		{name: "record already gone"},
+		{
+			name:     "only a later record listed",
+			listings: [][]route53types.ResourceRecordSet{recordSet("www.example.com.", route53types.RRTypeA, 60, "54.162.153.80")},
+		},
+		{
+			name:     "record taken over",
+			listings: [][]route53types.ResourceRecordSet{recordSet("api.example.com.", route53types.RRTypeA, 60, "54.162.153.81")},
+		},
+		{name: "hosted zone gone", listErr: &route53types.NoSuchHostedZone{Message: new("No hosted zone found")}},
+		{name: "list denied", listErr: errors.New("AccessDenied"), wantErrOp: "ListResourceRecordSets"},
+		{
+			name:       "record changed before the delete",
+			listings:   [][]route53types.ResourceRecordSet{held},
+			err:        invalid,
+			wantDelete: true,
+		},
+		{
+			name:       "delete rejected for another reason",
+			listings:   [][]route53types.ResourceRecordSet{held, held},
+			err:        invalid,
+			wantDelete: true,
+			wantErrOp:  "ChangeResourceRecordSets",
+		},
+		{
+			name:       "delete denied",
+			listings:   [][]route53types.ResourceRecordSet{held},
+			err:        errors.New("AccessDenied"),
+			wantDelete: true,
+			wantErrOp:  "ChangeResourceRecordSets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeRoute53{listings: tt.listings, listErr: tt.listErr, err: tt.err}
+			err := NewRoute53Provider(client, "Z0123456789ABC").DeleteRecord(context.Background(), record)
+			if (err != nil) != (tt.wantErrOp != "") {
+				t.Fatalf("error = %v, want error from %q", err, tt.wantErrOp)
+			}
+			if apiErr, ok := errors.AsType[*APICallError](err); err != nil && (!ok || apiErr.Operation != tt.wantErrOp) {
+				t.Fatalf("error = %v, want an APICallError for %s", err, tt.wantErrOp)
+			}
+			if !tt.wantDelete {
+				if len(client.inputs) != 0 {
+					t.Fatalf("expected no change, got %+v", client.inputs)
+				}
+				return
+			}
+			if len(client.inputs) != 1 || client.inputs[0].ChangeBatch.Changes[0].Action != route53types.ChangeActionDelete {
+				t.Fatalf("expected one DELETE change, got %+v", client.inputs)
+			}
+			if ttl := client.inputs[0].ChangeBatch.Changes[0].ResourceRecordSet.TTL; ttl == nil || *ttl != 300 {
+				t.Fatalf("TTL = %v, want the 300 read back", ttl)
+			}
+		})
+	}
+}
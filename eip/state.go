@@ -0,0 +1,55 @@
+package eip
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadIPv6State returns the IPv6 address recorded in path by WriteIPv6State.
+// A missing file yields the zero Addr and no error.
+func ReadIPv6State(path string) (netip.Addr, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return netip.Addr{}, nil
+	}
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("read IPv6 state file %s: %w", path, err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return netip.Addr{}, nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil || !addr.Is6() || addr.Is4In6() {
+		return netip.Addr{}, fmt.Errorf("IPv6 state file %s does not contain an IPv6 address: %q", path, value)
+	}
+	return addr, nil
+}
+
+// WriteIPv6State records addr in path, replacing the file atomically so a
+// crash never leaves a truncated address behind.
+func WriteIPv6State(path string, addr string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("write IPv6 state file %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.WriteString(addr + "\n"); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("write IPv6 state file %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write IPv6 state file %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write IPv6 state file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package eip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIPv6StateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ipv6")
+
+	got, err := ReadIPv6State(path)
+	if err != nil {
+		t.Fatalf("read missing state: %v", err)
+	}
+	if got.IsValid() {
+		t.Fatalf("missing state = %v, want zero address", got)
+	}
+
+	if err := WriteIPv6State(path, "2001:db8::1"); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+	if err := WriteIPv6State(path, "2001:db8::2"); err != nil {
+		t.Fatalf("overwrite state: %v", err)
+	}
+	got, err = ReadIPv6State(path)
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+	if got.String() != "2001:db8::2" {
+		t.Fatalf("state = %v, want 2001:db8::2", got)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("state dir has %d entries, want 1 (temp files leaked)", len(entries))
+	}
+}
+
+func TestReadIPv6StateRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ipv6")
+	if err := os.WriteFile(path, []byte("not-an-ip"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := ReadIPv6State(path); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
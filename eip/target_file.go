@@ -0,0 +1,97 @@
+package eip
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// TargetFilePrefix marks a CLI target that is read from a file, such as a
+// downward-API volume: "@/etc/podinfo/eip".
+const TargetFilePrefix = "@"
+
+// DefaultWatchInterval is how often TargetFileWatcher re-reads its file.
+const DefaultWatchInterval = 10 * time.Second
+
+// ReadTargetFile reads and normalizes the target stored in path. Surrounding
+// whitespace and a pair of double quotes, as written by downward-API volumes
+// for annotations, are trimmed.
+func ReadTargetFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read target file: %w", err)
+	}
+	value := strings.TrimSpace(string(data))
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	if value == "" {
+		return "", fmt.Errorf("target file %s is empty", path)
+	}
+	cfg, err := parseTarget(value)
+	if err != nil {
+		return "", fmt.Errorf("target file %s: %w", path, err)
+	}
+	return cfg.TargetIP, nil
+}
+
+// TargetFileWatcher polls a target file and reports content changes. Polling
+// is used instead of inotify because kubelet updates downward-API volumes by
+// swapping a symlink, which file watches on the target path miss.
+type TargetFileWatcher struct {
+	Path     string
+	Interval time.Duration
+	Logger   *log.Logger
+}
+
+// NewTargetFileWatcher creates a TargetFileWatcher for path.
+func NewTargetFileWatcher(path string, interval time.Duration, logger *log.Logger) *TargetFileWatcher {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	return &TargetFileWatcher{
+		Path:     path,
+		Interval: interval,
+		Logger:   logger,
+	}
+}
+
+// Watch calls onChange with the normalized target each time the file content
+// differs from current, until ctx is cancelled. Unreadable or invalid
+// content is logged and skipped. When onChange fails the change is retried on
+// the next poll.
+func (w *TargetFileWatcher) Watch(ctx context.Context, current string, onChange func(ctx context.Context, target string) error) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	w.Logger.Printf("Watching %s for target changes every %s", w.Path, w.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		target, err := ReadTargetFile(w.Path)
+		if err != nil {
+			w.Logger.Printf("watch: %v", err)
+			continue
+		}
+		if target == current {
+			continue
+		}
+
+		w.Logger.Printf("Target in %s changed from %s to %s", w.Path, current, target)
+		if err := onChange(ctx, target); err != nil {
+			w.Logger.Printf("watch: %v (retrying on next poll)", err)
+			continue
+		}
+		current = target
+	}
+}
@@ -0,0 +1,108 @@
+package eip
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadTargetFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "trims whitespace", content: " 54.162.153.80\n", want: "54.162.153.80"},
+		{name: "strips downward-API quotes", content: "\"2001:0db8::1\"\n", want: "2001:db8::1"},
+		{name: "IPv6 prefix", content: "2001:db8:0:0:1::/80", want: "2001:db8:0:0:1::/80"},
+		{name: "empty", content: "\n", wantErr: true},
+		{name: "invalid", content: "not-an-ip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "eip")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			got, err := ReadTargetFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("target = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ReadTargetFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected error for missing file, got nil")
+	}
+}
+
+func TestTargetFileWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eip")
+	writeTarget := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	writeTarget("54.162.153.80")
+
+	changes := make(chan string, 8)
+	failures := 1
+	onChange := func(_ context.Context, target string) error {
+		changes <- target
+		if target == "54.162.153.82" && failures > 0 {
+			failures--
+			return errors.New("throttled")
+		}
+		return nil
+	}
+	waitChange := func(want string) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Fatalf("change = %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for change to %q", want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	watcher := NewTargetFileWatcher(path, 5*time.Millisecond, silentLogger())
+	go func() { done <- watcher.Watch(ctx, "54.162.153.80", onChange) }()
+
+	writeTarget("garbage")
+	writeTarget("54.162.153.81\n")
+	waitChange("54.162.153.81")
+
+	// A failed change is retried on the next poll.
+	writeTarget("54.162.153.82")
+	waitChange("54.162.153.82")
+	waitChange("54.162.153.82")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	select {
+	case got := <-changes:
+		t.Fatalf("unexpected extra change %q", got)
+	default:
+	}
+}
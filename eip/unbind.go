@@ -0,0 +1,100 @@
+package eip
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Unbind releases the address recorded in result from its ENI. An IPv4
+// Elastic IP is disassociated, and an IPv6 address or prefix unassigned,
+// only if it is still on result.NetworkInterfaceID. When DNS is set and the
+// address was released, the record is then withdrawn. When Audit is set,
+// the unbind is recorded.
+//
+// released is false when the address had already left the ENI, so nothing
+// was released.
+func (b *Binder) Unbind(ctx context.Context, result *BindResult) (released bool, err error) {
+	_, err = b.audited(ctx, AuditOperationUnbind, result.TargetIP, func(b *Binder) (*BindResult, error) {
+		var err error
+		released, err = b.unbindAndWithdraw(ctx, result)
+		return result, err
+	})
+	return released, err
+}
+
+func (b *Binder) unbindAndWithdraw(ctx context.Context, result *BindResult) (bool, error) {
+	released, err := b.unbind(ctx, result)
+	if err != nil || !released {
+		return false, err
+	}
+	if b.DNS != nil {
+		return true, b.DNS.Withdraw(ctx, b.Logger, result)
+	}
+	return true, nil
+}
+
+func (b *Binder) unbind(ctx context.Context, result *BindResult) (bool, error) {
+	if result.Family == IPFamilyIPv4 {
+		return b.unbindIPv4(ctx, result)
+	}
+
+	eni, err := b.describeNetworkInterface(ctx, result.NetworkInterfaceID)
+	if err != nil {
+		return false, err
+	}
+	input := &ec2.UnassignIpv6AddressesInput{NetworkInterfaceId: new(result.NetworkInterfaceID)}
+	kind := "IPv6"
+	var held bool
+	if result.Prefix {
+		input.Ipv6Prefixes = []string{result.TargetIP}
+		kind = "IPv6 prefix"
+		prefix, err := netip.ParsePrefix(result.TargetIP)
+		held = err == nil && hasIPv6Prefix(eni, prefix.Masked())
+	} else {
+		input.Ipv6Addresses = []string{result.TargetIP}
+		held = hasIPv6(eni, result.TargetIP)
+	}
+	if !held {
+		b.Logger.Printf("%s %s is no longer assigned to ENI %s", kind, result.TargetIP, result.NetworkInterfaceID)
+		return false, nil
+	}
+	b.Logger.Printf("Unassigning %s %s from ENI %s", kind, result.TargetIP, result.NetworkInterfaceID)
+	if _, err := b.EC2.UnassignIpv6Addresses(ctx, input); err != nil {
+		return false, fmt.Errorf("unassign %s %s from ENI %s: %w", kind, result.TargetIP, result.NetworkInterfaceID,
+			newAPICallError("UnassignIpv6Addresses", err, result.NetworkInterfaceID, result.TargetIP))
+	}
+	return true, nil
+}
+
+func (b *Binder) unbindIPv4(ctx context.Context, result *BindResult) (bool, error) {
+	out, err := b.addressEC2().DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+		PublicIps: []string{result.TargetIP},
+	})
+	if err != nil {
+		return false, fmt.Errorf("describe addresses for %s: %w", result.TargetIP, newAPICallError("DescribeAddresses", err, result.TargetIP))
+	}
+	var address *types.Address
+	for i := range out.Addresses {
+		if out.Addresses[i].NetworkInterfaceId != nil && *out.Addresses[i].NetworkInterfaceId == result.NetworkInterfaceID {
+			address = &out.Addresses[i]
+			break
+		}
+	}
+	if address == nil || address.AssociationId == nil {
+		b.Logger.Printf("EIP %s is no longer associated with ENI %s", result.TargetIP, result.NetworkInterfaceID)
+		return false, nil
+	}
+
+	b.Logger.Printf("Disassociating EIP %s (association=%s) from ENI %s", result.TargetIP, *address.AssociationId, result.NetworkInterfaceID)
+	if _, err := b.addressEC2().DisassociateAddress(ctx, &ec2.DisassociateAddressInput{
+		AssociationId: address.AssociationId,
+	}); err != nil {
+		return false, fmt.Errorf("disassociate EIP %s from ENI %s: %w", result.TargetIP, result.NetworkInterfaceID,
+			newAPICallError("DisassociateAddress", err, *address.AssociationId, result.NetworkInterfaceID))
+	}
+	return true, nil
+}
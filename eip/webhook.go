@@ -0,0 +1,200 @@
+package eip
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultWebhookTimeout     = 10 * time.Second
+	DefaultWebhookMaxAttempts = 3
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// request body keyed with the webhook secret.
+	WebhookSignatureHeader = "X-EIP-Binding-Signature"
+)
+
+// Reasons reported in OwnershipChange.Reason.
+const (
+	ChangeReasonBind          = "bind"
+	ChangeReasonTargetChanged = "target-changed"
+	ChangeReasonHandoff       = "interruption-handoff"
+	ChangeReasonRelease       = "interruption-release"
+)
+
+// webhookBackoff is the delay before the second attempt; it doubles after
+// each further failure.
+const webhookBackoff = time.Second
+
+// OwnershipChange is the JSON payload of a webhook notification. The new
+// instance and ENI are empty when the address was released.
+type OwnershipChange struct {
+	Address                    string    `json:"address"`
+	Family                     string    `json:"family"`
+	PreviousInstanceID         string    `json:"previous_instance_id,omitempty"`
+	PreviousNetworkInterfaceID string    `json:"previous_network_interface_id,omitempty"`
+	InstanceID                 string    `json:"instance_id,omitempty"`
+	NetworkInterfaceID         string    `json:"network_interface_id,omitempty"`
+	Timestamp                  time.Time `json:"timestamp"`
+	Reason                     string    `json:"reason"`
+}
+
+// BindChange returns the change a Bind made, or nil when the address was
+// already associated and nothing moved.
+func BindChange(result *BindResult, reason string, now time.Time) *OwnershipChange {
+	if result.AlreadyAssociated {
+		return nil
+	}
+	return &OwnershipChange{
+		Address:                    result.TargetIP,
+		Family:                     result.Family,
+		PreviousInstanceID:         result.PreviousInstanceID,
+		PreviousNetworkInterfaceID: result.PreviousNetworkInterfaceID,
+		InstanceID:                 result.InstanceID,
+		NetworkInterfaceID:         result.NetworkInterfaceID,
+		Timestamp:                  now.UTC(),
+		Reason:                     reason,
+	}
+}
+
+// UnbindChange returns the change of releasing result's address.
+func UnbindChange(result *BindResult, reason string, now time.Time) *OwnershipChange {
+	return &OwnershipChange{
+		Address:                    result.TargetIP,
+		Family:                     result.Family,
+		PreviousInstanceID:         result.InstanceID,
+		PreviousNetworkInterfaceID: result.NetworkInterfaceID,
+		Timestamp:                  now.UTC(),
+		Reason:                     reason,
+	}
+}
+
+// Webhook posts OwnershipChange notifications to URL.
+type Webhook struct {
+	URL string
+	// Secret, when set, signs each request in WebhookSignatureHeader.
+	Secret string
+	// Timeout bounds each attempt. Zero uses DefaultWebhookTimeout.
+	Timeout time.Duration
+	// MaxAttempts includes the first attempt. Zero uses
+	// DefaultWebhookMaxAttempts.
+	MaxAttempts int
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+
+	backoff time.Duration
+}
+
+// webhookStatusError is a non-2xx webhook response.
+type webhookStatusError struct {
+	status int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook returned HTTP %d", e.status)
+}
+
+// retryable reports whether a later attempt may succeed.
+func (e *webhookStatusError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// Notify posts change, retrying transport errors, HTTP 429, and HTTP 5xx
+// responses with exponential backoff.
+func (w *Webhook) Notify(ctx context.Context, change *OwnershipChange) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	maxAttempts := w.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultWebhookMaxAttempts
+	}
+	backoff := w.backoff
+	if backoff <= 0 {
+		backoff = webhookBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if statusErr, ok := errors.AsType[*webhookStatusError](err); ok && !statusErr.retryable() {
+			return fmt.Errorf("webhook: %w", err)
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("webhook: giving up after %d attempts: %w", attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// NotifyChange posts change to webhook, logging failures. It does nothing
+// when there is no webhook or nothing changed, so callers can pass the
+// result of BindChange directly.
+func NotifyChange(ctx context.Context, logger *log.Logger, webhook *Webhook, change *OwnershipChange) {
+	if webhook == nil || change == nil {
+		return
+	}
+	if err := webhook.Notify(ctx, change); err != nil {
+		logger.Printf("%v", err)
+		return
+	}
+	logger.Printf("Notified webhook of %s %s (%s)", change.Family, change.Address, change.Reason)
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) error {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(w.Secret, body))
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &webhookStatusError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the WebhookSignatureHeader value for body.
+// Receivers recompute it over the raw request body and compare with
+// hmac.Equal.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,114 @@
+package eip
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBindChange(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	result := &BindResult{
+		InstanceID:                 "i-new",
+		Family:                     IPFamilyIPv4,
+		TargetIP:                   "54.162.153.80",
+		NetworkInterfaceID:         "eni-new",
+		PreviousInstanceID:         "i-old",
+		PreviousNetworkInterfaceID: "eni-old",
+	}
+
+	got := BindChange(result, ChangeReasonBind, now)
+	want := OwnershipChange{
+		Address:                    "54.162.153.80",
+		Family:                     IPFamilyIPv4,
+		PreviousInstanceID:         "i-old",
+		PreviousNetworkInterfaceID: "eni-old",
+		InstanceID:                 "i-new",
+		NetworkInterfaceID:         "eni-new",
+		Timestamp:                  now.UTC(),
+		Reason:                     ChangeReasonBind,
+	}
+	if got == nil || *got != want {
+		t.Fatalf("change = %+v, want %+v", got, want)
+	}
+
+	result.AlreadyAssociated = true
+	if got := BindChange(result, ChangeReasonBind, now); got != nil {
+		t.Fatalf("change for already associated result = %+v, want nil", got)
+	}
+
+	released := UnbindChange(result, ChangeReasonRelease, now)
+	if released.PreviousInstanceID != "i-new" || released.PreviousNetworkInterfaceID != "eni-new" || released.InstanceID != "" || released.NetworkInterfaceID != "" {
+		t.Fatalf("release change = %+v, want the bound instance as previous owner", released)
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	change := &OwnershipChange{
+		Address:    "54.162.153.80",
+		Family:     IPFamilyIPv4,
+		InstanceID: "i-new",
+		Timestamp:  time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC),
+		Reason:     ChangeReasonBind,
+	}
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{name: "success", statuses: []int{http.StatusNoContent}, wantAttempts: 1},
+		{name: "retries server errors", statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}, wantAttempts: 3},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500, 200}, wantErr: true, wantAttempts: 3},
+		{name: "does not retry client errors", statuses: []int{http.StatusBadRequest, http.StatusOK}, wantErr: true, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("read body: %v", err)
+				}
+				if got, want := r.Header.Get(WebhookSignatureHeader), SignWebhookPayload("s3cret", body); got != want {
+					t.Errorf("signature = %q, want %q", got, want)
+				}
+				var got OwnershipChange
+				if err := json.Unmarshal(body, &got); err != nil || got != *change {
+					t.Errorf("payload = %s (%v), want %+v", body, err, change)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			webhook := &Webhook{URL: server.URL, Secret: "s3cret", backoff: time.Millisecond}
+			err := webhook.Notify(context.Background(), change)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// echo -n '{"address":"54.162.153.80"}' | openssl dgst -sha256 -hmac s3cret
+	got := SignWebhookPayload("s3cret", []byte(`{"address":"54.162.153.80"}`))
+	want := "sha256=4a9343d4fd7427af3cf496970bd39aad7f2fd6e2c51e0d85e6b0f67cdf0fec3a"
+	if got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package kube
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+const controllerUsageLine = "usage: aws-eip-binding controller [flags]"
+
+// ControllerConfig holds the resolved configuration for controller mode.
+type ControllerConfig struct {
+	// NodeName is the Kubernetes node whose pods are bound to this instance.
+	NodeName string
+	// Annotation is the pod annotation holding the address to bind.
+	Annotation string
+	// Webhook is notified whenever a pod's address moves to this node. Nil
+	// disables notifications.
+	Webhook *eip.Webhook
+	// Audit, when set, appends a record of every bind to a file.
+	Audit *eip.AuditOptions
+	// AWS holds the AWS client settings from the flags of
+	// eip.RegisterAWSFlags. Its target fields are unused.
+	AWS eip.Config
+}
+
+// ParseControllerConfig resolves controller settings from CLI arguments and
+// environment variables. The node name defaults to the NODE_NAME environment
+// variable, which is typically set from spec.nodeName via the downward API.
+func ParseControllerConfig(args []string, getenv func(string) string) (*ControllerConfig, error) {
+	fs := flag.NewFlagSet("aws-eip-binding controller", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	nodeName := fs.String("node-name", getenv("NODE_NAME"), "node whose pods are bound to this instance (default $NODE_NAME)")
+	annotation := fs.String("annotation", AddressAnnotation, "pod annotation holding the address to bind")
+	webhookURL := fs.String("webhook-url", "", "URL notified with a JSON POST whenever an address moves")
+	webhookSecret := fs.String("webhook-secret", getenv("EIP_BINDING_WEBHOOK_SECRET"), "HMAC-SHA256 key signing webhook requests (default $EIP_BINDING_WEBHOOK_SECRET)")
+	webhookTimeout := fs.Duration("webhook-timeout", eip.DefaultWebhookTimeout, "timeout for each webhook attempt")
+	webhookMaxAttempts := fs.Int("webhook-max-attempts", eip.DefaultWebhookMaxAttempts, "webhook attempts, including the first")
+	auditLog := fs.String("audit-log", "", "file that a JSON record of every bind is appended to")
+	auditLogMaxSize := fs.Int64("audit-log-max-size", eip.DefaultAuditMaxBytes>>20, "size in megabytes at which -audit-log is rotated (0 disables rotation)")
+	auditLogMaxBackups := fs.Int("audit-log-max-backups", eip.DefaultAuditMaxBackups, "rotated -audit-log files to keep")
+	awsFlags := eip.RegisterAWSFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, controllerUsageError(fs)
+		}
+		return nil, fmt.Errorf("%w\n%s", err, controllerUsageError(fs))
+	}
+	if fs.NArg() != 0 {
+		return nil, controllerUsageError(fs)
+	}
+	if *nodeName == "" {
+		return nil, errors.New("controller: node name is empty (set -node-name or NODE_NAME)")
+	}
+	if *annotation == "" {
+		return nil, errors.New("controller: annotation is empty")
+	}
+	webhook, err := eip.ParseWebhook(*webhookURL, *webhookSecret, *webhookTimeout, *webhookMaxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("controller: %w", err)
+	}
+	audit, err := eip.ParseAudit(*auditLog, *auditLogMaxSize, *auditLogMaxBackups)
+	if err != nil {
+		return nil, fmt.Errorf("controller: %w", err)
+	}
+	cfg := &ControllerConfig{NodeName: *nodeName, Annotation: *annotation, Webhook: webhook, Audit: audit}
+	if err := awsFlags.Apply(&cfg.AWS); err != nil {
+		return nil, fmt.Errorf("controller: %w", err)
+	}
+	return cfg, nil
+}
+
+func controllerUsageError(fs *flag.FlagSet) error {
+	var b strings.Builder
+	b.WriteString(controllerUsageLine)
+	b.WriteString("\n\nflags:\n")
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+	return errors.New(strings.TrimRight(b.String(), "\n"))
+}
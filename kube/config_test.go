@@ -0,0 +1,117 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+func TestParseControllerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    ControllerConfig
+		wantErr bool
+	}{
+		{
+			name: "node name from environment",
+			env:  map[string]string{"NODE_NAME": "ip-10-0-0-1"},
+			want: ControllerConfig{NodeName: "ip-10-0-0-1", Annotation: AddressAnnotation},
+		},
+		{
+			name: "flags override defaults",
+			args: []string{"-node-name", "node-a", "-annotation", "example.com/eip"},
+			env:  map[string]string{"NODE_NAME": "ip-10-0-0-1"},
+			want: ControllerConfig{NodeName: "node-a", Annotation: "example.com/eip"},
+		},
+		{
+			name:    "zero audit log backups",
+			args:    []string{"-audit-log", "audit.jsonl", "-audit-log-max-backups", "0"},
+			env:     map[string]string{"NODE_NAME": "ip-10-0-0-1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid webhook URL",
+			args:    []string{"-webhook-url", "hooks.example.com"},
+			env:     map[string]string{"NODE_NAME": "ip-10-0-0-1"},
+			wantErr: true,
+		},
+		{
+			name:    "missing node name",
+			wantErr: true,
+		},
+		{
+			name:    "unexpected positional argument",
+			args:    []string{"54.162.153.80"},
+			env:     map[string]string{"NODE_NAME": "ip-10-0-0-1"},
+			wantErr: true,
+		},
+		{
+			name:    "empty annotation",
+			args:    []string{"-annotation", ""},
+			env:     map[string]string{"NODE_NAME": "ip-10-0-0-1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseControllerConfig(tt.args, func(key string) string { return tt.env[key] })
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("config = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseControllerConfigWebhook(t *testing.T) {
+	got, err := ParseControllerConfig([]string{"-webhook-url", "https://hooks.example.com/eip", "-webhook-max-attempts", "5"},
+		func(key string) string {
+			return map[string]string{"NODE_NAME": "ip-10-0-0-1", "EIP_BINDING_WEBHOOK_SECRET": "s3cret"}[key]
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := eip.Webhook{URL: "https://hooks.example.com/eip", Secret: "s3cret", Timeout: eip.DefaultWebhookTimeout, MaxAttempts: 5}
+	if got.Webhook == nil || *got.Webhook != want {
+		t.Fatalf("webhook = %+v, want %+v", got.Webhook, want)
+	}
+}
+
+func TestParseControllerConfigAudit(t *testing.T) {
+	got, err := ParseControllerConfig([]string{"-audit-log", "/var/log/eip-binding/audit.jsonl", "-audit-log-max-size", "1"},
+		func(key string) string { return map[string]string{"NODE_NAME": "ip-10-0-0-1"}[key] })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := eip.AuditOptions{Path: "/var/log/eip-binding/audit.jsonl", MaxBytes: 1 << 20, MaxBackups: eip.DefaultAuditMaxBackups}
+	if got.Audit == nil || *got.Audit != want {
+		t.Fatalf("audit = %+v, want %+v", got.Audit, want)
+	}
+}
+
+func TestParseControllerConfigAWS(t *testing.T) {
+	got, err := ParseControllerConfig([]string{
+		"-role-arn", "arn:aws:iam::111122223333:role/eip", "-ec2-endpoint", "https://vpce.example.com",
+		"-network-stack", "ipv6", "-imds-disable-v1",
+	}, func(key string) string { return map[string]string{"NODE_NAME": "ip-10-0-0-1"}[key] })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.AWS.Role == nil || got.AWS.Role.RoleARN != "arn:aws:iam::111122223333:role/eip" {
+		t.Fatalf("role = %+v, want arn:aws:iam::111122223333:role/eip", got.AWS.Role)
+	}
+	if got.AWS.EC2Endpoint != "https://vpce.example.com" || got.AWS.NetworkStack != eip.NetworkStackIPv6 || !got.AWS.IMDS.DisableV1 {
+		t.Fatalf("AWS config = %+v", got.AWS)
+	}
+}
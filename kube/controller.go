@@ -0,0 +1,200 @@
+// Package kube drives EIP binding from Kubernetes pod annotations.
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+// AddressAnnotation is the default pod annotation holding the address to bind.
+const AddressAnnotation = "eip-binding/address"
+
+// Binder binds a target address to the current instance.
+type Binder interface {
+	Bind(ctx context.Context, targetIP string) (*eip.BindResult, error)
+}
+
+// PodAddress returns the trimmed value of annotation on the named pod.
+func PodAddress(ctx context.Context, client kubernetes.Interface, namespace, name, annotation string) (string, error) {
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get pod %s/%s: %w", namespace, name, err)
+	}
+	value := strings.TrimSpace(pod.Annotations[annotation])
+	if value == "" {
+		return "", fmt.Errorf("pod %s/%s has no %s annotation", namespace, name, annotation)
+	}
+	return value, nil
+}
+
+// Controller watches the pods scheduled on one node and binds the address in
+// each pod's annotation to the node's instance. It is meant to run as a
+// DaemonSet so every node binds the addresses of its own pods.
+type Controller struct {
+	Client     kubernetes.Interface
+	NodeName   string
+	Annotation string
+	Binder     Binder
+	Logger     *log.Logger
+	// Webhook, when set, is notified of each bind that moves an address.
+	Webhook *eip.Webhook
+
+	queue workqueue.TypedRateLimitingInterface[string]
+	pods  corelisters.PodLister
+	// bound maps pod keys to the address last bound for them, so resyncs and
+	// unrelated pod updates do not call EC2 again.
+	bound map[string]string
+}
+
+// NewController creates a Controller for the pods on nodeName.
+func NewController(client kubernetes.Interface, nodeName string, binder Binder, logger *log.Logger) *Controller {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &Controller{
+		Client:     client,
+		NodeName:   nodeName,
+		Annotation: AddressAnnotation,
+		Binder:     binder,
+		Logger:     logger,
+	}
+}
+
+// Run watches pods until ctx is cancelled. Binds are processed one at a time
+// because they all target the same instance.
+func (c *Controller) Run(ctx context.Context) error {
+	if c.NodeName == "" {
+		return errors.New("controller: node name is empty")
+	}
+
+	c.queue = workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]())
+	c.bound = make(map[string]string)
+
+	factory := informers.NewSharedInformerFactoryWithOptions(c.Client, 0,
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", c.NodeName).String()
+		}),
+	)
+	podInformer := factory.Core().V1().Pods()
+	c.pods = podInformer.Lister()
+	_, err := podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, obj any) { c.enqueue(obj) },
+		DeleteFunc: c.enqueue,
+	})
+	if err != nil {
+		return fmt.Errorf("controller: add pod event handler: %w", err)
+	}
+
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	if !cache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+		return fmt.Errorf("controller: wait for pod cache sync: %w", ctx.Err())
+	}
+	c.Logger.Printf("Watching pods on node %s for annotation %s", c.NodeName, c.Annotation)
+
+	go func() {
+		<-ctx.Done()
+		c.queue.ShutDown()
+	}()
+	for c.processNext(ctx) {
+	}
+	return nil
+}
+
+func (c *Controller) enqueue(obj any) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		c.Logger.Printf("controller: %v", err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) processNext(ctx context.Context) bool {
+	key, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(ctx, key); err != nil {
+		c.Logger.Printf("controller: sync pod %s: %v (retrying)", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) sync(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.Logger.Printf("controller: %v", err)
+		return nil
+	}
+	pod, err := c.pods.Pods(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		delete(c.bound, key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	address := c.podAddress(pod)
+	if address == "" {
+		delete(c.bound, key)
+		return nil
+	}
+	// Retrying cannot fix a malformed annotation; the next pod update will
+	// bring a new value.
+	address, err = eip.NormalizeTarget(address)
+	if err != nil {
+		c.Logger.Printf("controller: pod %s: %s annotation: %v (ignoring)", key, c.Annotation, err)
+		delete(c.bound, key)
+		return nil
+	}
+	if c.bound[key] == address {
+		return nil
+	}
+
+	c.Logger.Printf("Binding %s for pod %s", address, key)
+	result, err := c.Binder.Bind(ctx, address)
+	if err != nil {
+		return err
+	}
+	c.bound[key] = address
+	c.Logger.Printf("Bound %s %s to ENI %s on instance %s for pod %s (already associated=%t)",
+		result.Family, result.TargetIP, result.NetworkInterfaceID, result.InstanceID, key, result.AlreadyAssociated)
+	eip.NotifyChange(ctx, c.Logger, c.Webhook, eip.BindChange(result, eip.ChangeReasonBind, time.Now()))
+	return nil
+}
+
+// podAddress returns the address to bind for pod, or "" when the pod should
+// be ignored.
+func (c *Controller) podAddress(pod *corev1.Pod) string {
+	if pod.Spec.NodeName != c.NodeName || pod.DeletionTimestamp != nil {
+		return ""
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return ""
+	}
+	return strings.TrimSpace(pod.Annotations[c.Annotation])
+}
@@ -0,0 +1,249 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+type fakeBinder struct {
+	mu    sync.Mutex
+	calls []string
+	errs  map[string]error
+	bound chan string
+}
+
+func newFakeBinder() *fakeBinder {
+	return &fakeBinder{bound: make(chan string, 16)}
+}
+
+func (f *fakeBinder) Bind(_ context.Context, targetIP string) (*eip.BindResult, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, targetIP)
+	err := f.errs[targetIP]
+	delete(f.errs, targetIP)
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	f.bound <- targetIP
+	return &eip.BindResult{TargetIP: targetIP, InstanceID: "i-node", NetworkInterfaceID: "eni-node"}, nil
+}
+
+func (f *fakeBinder) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func (f *fakeBinder) waitBound(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case got := <-f.bound:
+		if got != want {
+			t.Fatalf("bound %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for bind of %q", want)
+	}
+}
+
+func (f *fakeBinder) expectNoBind(t *testing.T) {
+	t.Helper()
+	select {
+	case got := <-f.bound:
+		t.Fatalf("unexpected bind of %q", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func pod(name, node, address string) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if address != "" {
+		p.Annotations = map[string]string{AddressAnnotation: address}
+	}
+	return p
+}
+
+func silentLogger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
+
+func TestControllerBindsAnnotatedPodsOnNode(t *testing.T) {
+	client := fake.NewClientset(
+		pod("web-0", "node-a", "54.162.153.80"),
+		pod("web-1", "node-b", "54.162.153.81"),
+		pod("plain", "node-a", ""),
+	)
+	binder := newFakeBinder()
+	controller := NewController(client, "node-a", binder, silentLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- controller.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	})
+
+	binder.waitBound(t, "54.162.153.80")
+	binder.expectNoBind(t)
+
+	// Updating the annotation rebinds; unrelated updates do not.
+	updated := pod("web-0", "node-a", "2001:db8::1")
+	updated.Labels = map[string]string{"rev": "2"}
+	if _, err := client.CoreV1().Pods("default").Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+	binder.waitBound(t, "2001:db8::1")
+
+	updated.Labels["rev"] = "3"
+	if _, err := client.CoreV1().Pods("default").Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+	binder.expectNoBind(t)
+
+	// A pod scheduled later is picked up.
+	if _, err := client.CoreV1().Pods("default").Create(ctx, pod("web-2", "node-a", "54.162.153.82"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create pod: %v", err)
+	}
+	binder.waitBound(t, "54.162.153.82")
+}
+
+func TestControllerRetriesFailedBind(t *testing.T) {
+	client := fake.NewClientset(pod("web-0", "node-a", "54.162.153.80"))
+	binder := newFakeBinder()
+	binder.errs = map[string]error{"54.162.153.80": errors.New("throttled")}
+	controller := NewController(client, "node-a", binder, silentLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- controller.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	binder.waitBound(t, "54.162.153.80")
+	if got := binder.callCount(); got != 2 {
+		t.Fatalf("Bind calls = %d, want 2", got)
+	}
+}
+
+func TestControllerIgnoresFinishedPods(t *testing.T) {
+	finished := pod("job", "node-a", "54.162.153.80")
+	finished.Status.Phase = corev1.PodSucceeded
+	client := fake.NewClientset(finished)
+	binder := newFakeBinder()
+	controller := NewController(client, "node-a", binder, silentLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- controller.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	binder.expectNoBind(t)
+}
+
+func TestControllerSkipsInvalidAnnotation(t *testing.T) {
+	client := fake.NewClientset(pod("web-0", "node-a", "not-an-ip"))
+	binder := newFakeBinder()
+	controller := NewController(client, "node-a", binder, silentLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- controller.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	binder.expectNoBind(t)
+	if got := binder.callCount(); got != 0 {
+		t.Fatalf("Bind calls = %d, want 0", got)
+	}
+
+	// A corrected annotation is picked up on the next update.
+	if _, err := client.CoreV1().Pods("default").Update(ctx, pod("web-0", "node-a", "54.162.153.80"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+	binder.waitBound(t, "54.162.153.80")
+}
+
+func TestControllerNotifiesWebhook(t *testing.T) {
+	changes := make(chan eip.OwnershipChange, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var change eip.OwnershipChange
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		changes <- change
+	}))
+	t.Cleanup(server.Close)
+
+	client := fake.NewClientset(pod("web-0", "node-a", "54.162.153.80"))
+	binder := newFakeBinder()
+	controller := NewController(client, "node-a", binder, silentLogger())
+	controller.Webhook = &eip.Webhook{URL: server.URL}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- controller.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	select {
+	case change := <-changes:
+		if change.Address != "54.162.153.80" || change.InstanceID != "i-node" || change.Reason != eip.ChangeReasonBind {
+			t.Fatalf("change = %+v, want bind of 54.162.153.80 to i-node", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for webhook notification")
+	}
+}
+
+func TestPodAddress(t *testing.T) {
+	client := fake.NewClientset(
+		pod("web-0", "node-a", " 54.162.153.80\n"),
+		pod("plain", "node-a", ""),
+	)
+
+	got, err := PodAddress(context.Background(), client, "default", "web-0", AddressAnnotation)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "54.162.153.80" {
+		t.Fatalf("address = %q, want 54.162.153.80", got)
+	}
+
+	if _, err := PodAddress(context.Background(), client, "default", "plain", AddressAnnotation); err == nil {
+		t.Fatal("expected error for pod without annotation, got nil")
+	}
+	if _, err := PodAddress(context.Background(), client, "default", "missing", AddressAnnotation); err == nil {
+		t.Fatal("expected error for missing pod, got nil")
+	}
+}
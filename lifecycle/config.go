@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+// HandlerConfig holds the resolved configuration for the Lambda handler.
+type HandlerConfig struct {
+	// TagKey is the instance tag holding the address to bind.
+	TagKey string
+	// FailureResult completes launch lifecycle actions whose bind failed.
+	FailureResult string
+	// Webhook is notified whenever an address moves. Nil disables
+	// notifications.
+	Webhook *eip.Webhook
+}
+
+// ParseHandlerConfig resolves handler settings from environment variables,
+// since Lambda functions take no arguments:
+//
+//   - EIP_BINDING_TAG: instance tag holding the address (default
+//     eip.DefaultInstanceTag)
+//   - EIP_BINDING_FAILURE_RESULT: ABANDON (default) or CONTINUE
+//   - EIP_BINDING_WEBHOOK_URL and EIP_BINDING_WEBHOOK_SECRET: webhook
+//     notified of each move, with the default timeout and attempts
+func ParseHandlerConfig(getenv func(string) string) (*HandlerConfig, error) {
+	cfg := &HandlerConfig{
+		TagKey:        getenv("EIP_BINDING_TAG"),
+		FailureResult: getenv("EIP_BINDING_FAILURE_RESULT"),
+	}
+	if cfg.TagKey == "" {
+		cfg.TagKey = eip.DefaultInstanceTag
+	}
+	switch cfg.FailureResult {
+	case "":
+		cfg.FailureResult = ResultAbandon
+	case ResultAbandon, ResultContinue:
+	default:
+		return nil, fmt.Errorf("EIP_BINDING_FAILURE_RESULT must be %s or %s, got %q", ResultAbandon, ResultContinue, cfg.FailureResult)
+	}
+	webhook, err := eip.ParseWebhook(getenv("EIP_BINDING_WEBHOOK_URL"), getenv("EIP_BINDING_WEBHOOK_SECRET"),
+		eip.DefaultWebhookTimeout, eip.DefaultWebhookMaxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("EIP_BINDING_WEBHOOK_URL: %w", err)
+	}
+	cfg.Webhook = webhook
+	return cfg, nil
+}
@@ -0,0 +1,66 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+func TestParseHandlerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    HandlerConfig
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: HandlerConfig{TagKey: eip.DefaultInstanceTag, FailureResult: ResultAbandon},
+		},
+		{
+			name: "overrides",
+			env:  map[string]string{"EIP_BINDING_TAG": "eip", "EIP_BINDING_FAILURE_RESULT": "CONTINUE"},
+			want: HandlerConfig{TagKey: "eip", FailureResult: ResultContinue},
+		},
+		{
+			name:    "invalid webhook URL",
+			env:     map[string]string{"EIP_BINDING_WEBHOOK_URL": "hooks.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid failure result",
+			env:     map[string]string{"EIP_BINDING_FAILURE_RESULT": "RETRY"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHandlerConfig(func(key string) string { return tt.env[key] })
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("config = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHandlerConfigWebhook(t *testing.T) {
+	env := map[string]string{"EIP_BINDING_WEBHOOK_URL": "https://hooks.example.com/eip", "EIP_BINDING_WEBHOOK_SECRET": "s3cret"}
+	got, err := ParseHandlerConfig(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := eip.Webhook{URL: "https://hooks.example.com/eip", Secret: "s3cret", Timeout: eip.DefaultWebhookTimeout, MaxAttempts: eip.DefaultWebhookMaxAttempts}
+	if got.Webhook == nil || *got.Webhook != want {
+		t.Fatalf("webhook = %+v, want %+v", got.Webhook, want)
+	}
+}
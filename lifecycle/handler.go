@@ -0,0 +1,163 @@
+// Package lifecycle binds addresses to instances launched by Auto Scaling,
+// driven by EventBridge events delivered to a Lambda function.
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+// EventBridge detail types handled by Handler.
+const (
+	LaunchLifecycleActionDetailType = "EC2 Instance-launch Lifecycle Action"
+	StateChangeDetailType           = "EC2 Instance State-change Notification"
+)
+
+const launchingTransition = "autoscaling:EC2_INSTANCE_LAUNCHING"
+
+// Lifecycle action results passed to CompleteLifecycleAction.
+const (
+	ResultContinue = "CONTINUE"
+	ResultAbandon  = "ABANDON"
+)
+
+// AutoScalingAPI is the subset of the Auto Scaling client used by Handler.
+type AutoScalingAPI interface {
+	CompleteLifecycleAction(ctx context.Context, params *autoscaling.CompleteLifecycleActionInput, optFns ...func(*autoscaling.Options)) (*autoscaling.CompleteLifecycleActionOutput, error)
+}
+
+// LifecycleActionDetail is the detail of a launch lifecycle action event.
+type LifecycleActionDetail struct {
+	LifecycleActionToken string `json:"LifecycleActionToken"`
+	AutoScalingGroupName string `json:"AutoScalingGroupName"`
+	LifecycleHookName    string `json:"LifecycleHookName"`
+	EC2InstanceID        string `json:"EC2InstanceId"`
+	LifecycleTransition  string `json:"LifecycleTransition"`
+}
+
+// StateChangeDetail is the detail of an instance state-change event.
+type StateChangeDetail struct {
+	InstanceID string `json:"instance-id"`
+	State      string `json:"state"`
+}
+
+// Handler binds the address named by an instance tag to instances reported
+// by launch lifecycle actions and state-change events. It runs off-instance,
+// so the binder is pointed at the event's instance instead of reading IMDS.
+type Handler struct {
+	EC2         eip.EC2API
+	AutoScaling AutoScalingAPI
+	// TagKey is the instance tag holding the address to bind.
+	TagKey string
+	// FailureResult completes the lifecycle action when binding fails:
+	// ResultAbandon (the default) or ResultContinue.
+	FailureResult string
+	Logger        *log.Logger
+	// Webhook, when set, is notified of each bind that moves an address.
+	Webhook *eip.Webhook
+}
+
+// NewHandler creates a Handler that reads eip.DefaultInstanceTag.
+func NewHandler(ec2Client eip.EC2API, autoScalingClient AutoScalingAPI, logger *log.Logger) *Handler {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &Handler{
+		EC2:           ec2Client,
+		AutoScaling:   autoScalingClient,
+		TagKey:        eip.DefaultInstanceTag,
+		FailureResult: ResultAbandon,
+		Logger:        logger,
+	}
+}
+
+// Handle processes one EventBridge event. Launch lifecycle actions are always
+// completed, with FailureResult when binding fails; the bind error is still
+// returned so the invocation is reported as failed.
+func (h *Handler) Handle(ctx context.Context, event events.CloudWatchEvent) error {
+	switch event.DetailType {
+	case LaunchLifecycleActionDetailType:
+		var detail LifecycleActionDetail
+		if err := json.Unmarshal(event.Detail, &detail); err != nil {
+			return fmt.Errorf("decode %s: %w", event.DetailType, err)
+		}
+		return h.handleLaunch(ctx, &detail)
+	case StateChangeDetailType:
+		var detail StateChangeDetail
+		if err := json.Unmarshal(event.Detail, &detail); err != nil {
+			return fmt.Errorf("decode %s: %w", event.DetailType, err)
+		}
+		if detail.State != "running" {
+			h.Logger.Printf("Ignoring instance %s in state %s", detail.InstanceID, detail.State)
+			return nil
+		}
+		return h.bind(ctx, detail.InstanceID)
+	default:
+		return fmt.Errorf("unsupported event detail type %q", event.DetailType)
+	}
+}
+
+func (h *Handler) handleLaunch(ctx context.Context, detail *LifecycleActionDetail) error {
+	if detail.LifecycleTransition != launchingTransition {
+		h.Logger.Printf("Ignoring lifecycle transition %s for instance %s", detail.LifecycleTransition, detail.EC2InstanceID)
+		return nil
+	}
+
+	bindErr := h.bind(ctx, detail.EC2InstanceID)
+	result := ResultContinue
+	if bindErr != nil {
+		result = h.FailureResult
+	}
+
+	h.Logger.Printf("Completing lifecycle action %s for instance %s with %s", detail.LifecycleHookName, detail.EC2InstanceID, result)
+	_, err := h.AutoScaling.CompleteLifecycleAction(ctx, &autoscaling.CompleteLifecycleActionInput{
+		AutoScalingGroupName:  &detail.AutoScalingGroupName,
+		LifecycleHookName:     &detail.LifecycleHookName,
+		LifecycleActionToken:  &detail.LifecycleActionToken,
+		InstanceId:            &detail.EC2InstanceID,
+		LifecycleActionResult: &result,
+	})
+	if err != nil {
+		err = fmt.Errorf("complete lifecycle action %s for instance %s: %w", detail.LifecycleHookName, detail.EC2InstanceID, err)
+	}
+	return errors.Join(bindErr, err)
+}
+
+// bind moves the address tagged on instanceID to it. Instances without the
+// tag are skipped.
+func (h *Handler) bind(ctx context.Context, instanceID string) error {
+	if instanceID == "" {
+		return errors.New("event has no instance ID")
+	}
+	target, err := eip.ResolveInstanceTag(ctx, h.EC2, nil, instanceID, h.TagKey)
+	if errors.Is(err, eip.ErrInstanceTagNotFound) {
+		h.Logger.Printf("Skipping instance %s: %v", instanceID, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	binder := eip.NewBinder(h.EC2, nil, h.Logger)
+	binder.InstanceID = instanceID
+	result, err := binder.Bind(ctx, target)
+	if err != nil {
+		return fmt.Errorf("bind %s to instance %s: %w", target, instanceID, err)
+	}
+	if result.AlreadyAssociated {
+		h.Logger.Printf("%s %s already on instance %s", result.Family, result.TargetIP, result.InstanceID)
+	} else {
+		h.Logger.Printf("Bound %s %s to ENI %s on instance %s", result.Family, result.TargetIP, result.NetworkInterfaceID, result.InstanceID)
+	}
+	eip.NotifyChange(ctx, h.Logger, h.Webhook, eip.BindChange(result, eip.ChangeReasonBind, time.Now()))
+	return nil
+}
@@ -0,0 +1,191 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+const instanceID = "i-0123456789abcdef0"
+
+// fakeEC2 serves the calls an IPv4 bind makes: the instance tag, the Elastic
+// IP, and the instance's primary ENI. Other EC2API methods panic.
+type fakeEC2 struct {
+	eip.EC2API
+
+	tags         map[string]string
+	associateErr error
+	calls        []string
+}
+
+func (f *fakeEC2) DescribeTags(_ context.Context, in *ec2.DescribeTagsInput, _ ...func(*ec2.Options)) (*ec2.DescribeTagsOutput, error) {
+	f.calls = append(f.calls, "DescribeTags")
+	out := &ec2.DescribeTagsOutput{}
+	if value, ok := f.tags[filterValue(in.Filters, "key")]; ok && filterValue(in.Filters, "resource-id") == instanceID {
+		key := filterValue(in.Filters, "key")
+		out.Tags = []types.TagDescription{{Key: &key, Value: &value}}
+	}
+	return out, nil
+}
+
+func (f *fakeEC2) DescribeAddresses(_ context.Context, in *ec2.DescribeAddressesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	f.calls = append(f.calls, "DescribeAddresses")
+	return &ec2.DescribeAddressesOutput{Addresses: []types.Address{{
+		PublicIp:     &in.PublicIps[0],
+		AllocationId: new("eipalloc-web"),
+	}}}, nil
+}
+
+func (f *fakeEC2) DescribeNetworkInterfaces(_ context.Context, in *ec2.DescribeNetworkInterfacesInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	f.calls = append(f.calls, "DescribeNetworkInterfaces")
+	if got := filterValue(in.Filters, "attachment.instance-id"); got != instanceID {
+		return nil, errors.New("unexpected instance " + got)
+	}
+	return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: []types.NetworkInterface{{
+		NetworkInterfaceId: new("eni-web"),
+	}}}, nil
+}
+
+func (f *fakeEC2) AssociateAddress(_ context.Context, _ *ec2.AssociateAddressInput, _ ...func(*ec2.Options)) (*ec2.AssociateAddressOutput, error) {
+	f.calls = append(f.calls, "AssociateAddress")
+	if f.associateErr != nil {
+		return nil, f.associateErr
+	}
+	return &ec2.AssociateAddressOutput{AssociationId: new("eipassoc-web")}, nil
+}
+
+func filterValue(filters []types.Filter, name string) string {
+	for _, filter := range filters {
+		if filter.Name != nil && *filter.Name == name && len(filter.Values) == 1 {
+			return filter.Values[0]
+		}
+	}
+	return ""
+}
+
+type fakeAutoScaling struct {
+	completed []autoscaling.CompleteLifecycleActionInput
+}
+
+func (f *fakeAutoScaling) CompleteLifecycleAction(_ context.Context, in *autoscaling.CompleteLifecycleActionInput, _ ...func(*autoscaling.Options)) (*autoscaling.CompleteLifecycleActionOutput, error) {
+	f.completed = append(f.completed, *in)
+	return &autoscaling.CompleteLifecycleActionOutput{}, nil
+}
+
+func loadEvent(t *testing.T, name string) events.CloudWatchEvent {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event events.CloudWatchEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+	return event
+}
+
+func TestHandler(t *testing.T) {
+	errAssociate := errors.New("associate failed")
+
+	tests := []struct {
+		name          string
+		fixture       string
+		tags          map[string]string
+		associateErr  error
+		wantErr       error
+		wantAnyErr    bool
+		wantEC2Calls  []string
+		wantCompleted string
+	}{
+		{
+			name:          "launch lifecycle action binds and continues",
+			fixture:       "launch-lifecycle-action.json",
+			tags:          map[string]string{eip.DefaultInstanceTag: "54.162.153.80"},
+			wantEC2Calls:  []string{"DescribeTags", "DescribeAddresses", "DescribeNetworkInterfaces", "AssociateAddress"},
+			wantCompleted: ResultContinue,
+		},
+		{
+			name:          "launch lifecycle action abandons on bind failure",
+			fixture:       "launch-lifecycle-action.json",
+			tags:          map[string]string{eip.DefaultInstanceTag: "54.162.153.80"},
+			associateErr:  errAssociate,
+			wantErr:       errAssociate,
+			wantEC2Calls:  []string{"DescribeTags", "DescribeAddresses", "DescribeNetworkInterfaces", "AssociateAddress"},
+			wantCompleted: ResultAbandon,
+		},
+		{
+			name:          "launch lifecycle action without tag continues",
+			fixture:       "launch-lifecycle-action.json",
+			wantEC2Calls:  []string{"DescribeTags"},
+			wantCompleted: ResultContinue,
+		},
+		{
+			name:         "running instance binds",
+			fixture:      "state-change-running.json",
+			tags:         map[string]string{eip.DefaultInstanceTag: "54.162.153.80"},
+			wantEC2Calls: []string{"DescribeTags", "DescribeAddresses", "DescribeNetworkInterfaces", "AssociateAddress"},
+		},
+		{
+			name:    "stopping instance is ignored",
+			fixture: "state-change-stopping.json",
+			tags:    map[string]string{eip.DefaultInstanceTag: "54.162.153.80"},
+		},
+		{
+			name:       "unsupported detail type",
+			fixture:    "terminate-lifecycle-action.json",
+			wantAnyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2Fake := &fakeEC2{tags: tt.tags, associateErr: tt.associateErr}
+			autoScalingFake := &fakeAutoScaling{}
+			handler := NewHandler(ec2Fake, autoScalingFake, log.New(io.Discard, "", 0))
+
+			err := handler.Handle(context.Background(), loadEvent(t, tt.fixture))
+			if tt.wantAnyErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(ec2Fake.calls, tt.wantEC2Calls) {
+				t.Fatalf("EC2 calls = %v, want %v", ec2Fake.calls, tt.wantEC2Calls)
+			}
+
+			if tt.wantCompleted == "" {
+				if len(autoScalingFake.completed) != 0 {
+					t.Fatalf("unexpected CompleteLifecycleAction: %+v", autoScalingFake.completed)
+				}
+				return
+			}
+			if len(autoScalingFake.completed) != 1 {
+				t.Fatalf("CompleteLifecycleAction calls = %d, want 1", len(autoScalingFake.completed))
+			}
+			got := autoScalingFake.completed[0]
+			if *got.LifecycleActionResult != tt.wantCompleted {
+				t.Errorf("result = %q, want %q", *got.LifecycleActionResult, tt.wantCompleted)
+			}
+			if *got.AutoScalingGroupName != "web" || *got.LifecycleHookName != "bind-eip" ||
+				*got.LifecycleActionToken != "87654321-4321-4321-4321-210987654321" || *got.InstanceId != instanceID {
+				t.Errorf("unexpected CompleteLifecycleAction input: %+v", got)
+			}
+		})
+	}
+}
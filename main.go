@@ -2,75 +2,738 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	ec2imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/islishude/aws-eip-binding/eip"
+	"github.com/islishude/aws-eip-binding/kube"
+	"github.com/islishude/aws-eip-binding/lifecycle"
 )
 
 func main() {
 	logger := log.New(os.Stderr, "", log.LstdFlags)
 
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if len(os.Args) > 1 && os.Args[1] == "controller" {
+		runController(ctx, logger, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(ctx, logger, os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "policy" {
+		runPolicy(logger, os.Args[2:])
+		return
+	}
+	// Lambda custom runtimes start the bootstrap binary without arguments.
+	if (len(os.Args) > 1 && os.Args[1] == "lambda") || os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		runLambda(ctx, logger)
+		return
+	}
+
 	// Parse CLI arguments and environment variables.
 	cfg, err := eip.ParseConfigFromOS()
 	if err != nil {
 		logger.Fatalf("config: %v", err)
 	}
-	logger.Printf("Target IP: %s", cfg.TargetIP)
-
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
+	if cfg.TargetRef == eip.PodAnnotationTarget {
+		if err := resolvePodAnnotation(ctx, cfg); err != nil {
+			logger.Fatalf("config: %v", err)
+		}
+	}
+	if cfg.TargetIP != "" {
+		logger.Printf("Target IP: %s", cfg.TargetIP)
+	}
+	detectNetworkStack(logger, cfg)
 
 	// Load AWS configuration.
-	awsCfg, err := config.LoadDefaultConfig(ctx, awsLoadOptionsForConfig(cfg)...)
+	awsCfg, err := loadAWSConfig(ctx, logger, cfg)
 	if err != nil {
-		logger.Fatalf("loading AWS config: %v", err)
+		logger.Fatalf("%v", err)
+	}
+
+	if eip.IsRemoteTarget(cfg.TargetRef) {
+		roleCfg, err := awsConfigForRole(ctx, awsCfg, cfg)
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		resolver := eip.NewRemoteTargetResolver(ssm.NewFromConfig(roleCfg), secretsmanager.NewFromConfig(roleCfg))
+		target, err := resolver.ResolveTarget(ctx, cfg.TargetRef)
+		if err != nil {
+			logger.Fatalf("config: %v", err)
+		}
+		if err := cfg.SetTarget(target); err != nil {
+			logger.Fatalf("config: %v", err)
+		}
+		logger.Printf("Resolved %s: %s", cfg.TargetRef, cfg.TargetIP)
+		if _, err := reloadAWSConfigForFamily(ctx, logger, cfg, &awsCfg); err != nil {
+			logger.Fatalf("%v", err)
+		}
 	}
-	logger.Printf("AWS configuration loaded (region=%s)", awsCfg.Region)
 
 	// Create dependencies and bind.
-	ec2Client := ec2.NewFromConfig(awsCfg)
-	imds := ec2imds.NewFromConfig(awsCfg, imdsClientOptionsForConfig(cfg)...)
-	binder := eip.NewBinder(ec2Client, imds, logger)
+	binder, err := newBinder(ctx, logger, awsCfg, cfg)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+	if cfg.Instance != nil {
+		binder.InstanceID, err = eip.ResolveInstance(ctx, binder.EC2, cfg.Instance)
+		if err != nil {
+			logger.Fatalf("config: %v", err)
+		}
+		logger.Printf("Binding to instance %s (from -instance %s)", binder.InstanceID, cfg.Instance)
+	}
+
+	if cfg.TargetRef == eip.InstanceTagTarget || cfg.AddressPool != nil {
+		if err := resolveEC2Target(ctx, logger, cfg, binder); err != nil {
+			logger.Fatalf("config: %v", err)
+		}
+		// The clients that resolved the target were built before its family
+		// was known.
+		reloaded, err := reloadAWSConfigForFamily(ctx, logger, cfg, &awsCfg)
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		if reloaded {
+			instanceID := binder.InstanceID
+			if binder, err = newBinder(ctx, logger, awsCfg, cfg); err != nil {
+				logger.Fatalf("%v", err)
+			}
+			binder.InstanceID = instanceID
+		}
+	}
+	imds := binder.IMDS
+	binder.PrimaryIPv6 = cfg.PrimaryIPv6
+	binder.Hooks = cfg.Hooks
+	if cfg.DNS != nil {
+		roleCfg, err := awsConfigForRole(ctx, awsCfg, cfg)
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		binder.DNS = &eip.DNSUpdater{
+			Provider: eip.NewRoute53Provider(route53.NewFromConfig(roleCfg), cfg.DNS.HostedZoneID),
+			Name:     cfg.DNS.Name,
+			TTL:      cfg.DNS.TTL,
+		}
+	}
+	if cfg.Audit != nil {
+		audit, err := eip.OpenFileAuditSink(cfg.Audit.Path, cfg.Audit.MaxBytes, cfg.Audit.MaxBackups)
+		if err != nil {
+			logger.Fatalf("config: %v", err)
+		}
+		defer audit.Close()
+		binder.Audit = audit
+	}
+
+	var guest *eip.GuestConfigurator
+	if cfg.ConfigureOS {
+		network, err := eip.NewNetlinkNetwork()
+		if err != nil {
+			logger.Fatalf("configure OS: %v", err)
+		}
+		guest = eip.NewGuestConfigurator(imds, network, logger)
+	}
 
 	result, err := binder.Bind(ctx, cfg.TargetIP)
 	if err != nil {
-		logger.Fatalf("bind: %v", err)
+		reportError(logger, cfg.Output, fmt.Errorf("bind: %w", err))
+		os.Exit(1)
+	}
+	if guest != nil {
+		if err := guest.Configure(ctx, result); err != nil {
+			logger.Fatalf("configure OS: %v", err)
+		}
+	}
+
+	if cfg.IPv6StateFile != "" && cfg.TargetIP == eip.AutoIPv6 {
+		if err := eip.WriteIPv6State(cfg.IPv6StateFile, result.TargetIP); err != nil {
+			logger.Fatalf("state: %v", err)
+		}
+		logger.Printf("Recorded IPv6 %s in %s", result.TargetIP, cfg.IPv6StateFile)
+	}
+	reportResult(logger, cfg.Output, result)
+	eip.NotifyChange(ctx, logger, cfg.Webhook, eip.BindChange(result, eip.ChangeReasonBind, time.Now()))
+
+	// runCtx ends on SIGINT/SIGTERM or, with -on-interruption, when an
+	// interruption notice arrives.
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	if cfg.OnInterruption != nil {
+		interruptions := eip.NewInterruptionWatcher(imds, cfg.InterruptionInterval, logger)
+		go func() {
+			notice, err := interruptions.Watch(runCtx)
+			if err != nil {
+				logger.Printf("interruption: %v", err)
+			}
+			if notice != nil {
+				logger.Printf("Received %s notice", notice)
+				stop()
+			}
+		}()
+	}
+
+	if cfg.Watch {
+		watcher := eip.NewTargetFileWatcher(cfg.TargetFile, cfg.WatchInterval, logger)
+		err := watcher.Watch(runCtx, cfg.TargetIP, func(ctx context.Context, target string) error {
+			next, err := binder.Bind(ctx, target)
+			if err != nil {
+				err = fmt.Errorf("bind: %w", err)
+				// The watcher logs err itself.
+				reportErrorDetails(logger, cfg.Output, err)
+				return err
+			}
+			if guest != nil {
+				if err := guest.Configure(ctx, next); err != nil {
+					return fmt.Errorf("configure OS: %w", err)
+				}
+				if err := guest.Deconfigure(ctx, result); err != nil {
+					logger.Printf("configure OS: %v", err)
+				}
+			}
+			result = next
+			reportResult(logger, cfg.Output, result)
+			eip.NotifyChange(ctx, logger, cfg.Webhook, eip.BindChange(result, eip.ChangeReasonTargetChanged, time.Now()))
+			return nil
+		})
+		if err != nil {
+			logger.Fatalf("watch: %v", err)
+		}
+	} else if cfg.OnInterruption != nil {
+		<-runCtx.Done()
+	}
+
+	if cfg.OnInterruption != nil {
+		release(ctx, logger, cfg.Output, binder, guest, cfg.Webhook, result, cfg.OnInterruption)
+	}
+}
+
+// releaseTimeout bounds the -on-interruption action, leaving headroom in the
+// two-minute spot interruption notice.
+const releaseTimeout = 90 * time.Second
+
+// release applies the -on-interruption action to result. ctx may already be
+// cancelled by SIGTERM, so the action runs under its own deadline.
+func release(ctx context.Context, logger *log.Logger, output eip.OutputFormat, binder *eip.Binder, guest *eip.GuestConfigurator, webhook *eip.Webhook, result *eip.BindResult, action *eip.InterruptionAction) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+
+	logger.Printf("Releasing %s %s from instance %s (%s)", result.Family, result.TargetIP, result.InstanceID, action)
+	next, released, err := binder.Release(ctx, result, action)
+	if err != nil {
+		reportError(logger, output, fmt.Errorf("release: %w", err))
+		os.Exit(1)
+	}
+	if guest != nil {
+		if err := guest.Deconfigure(ctx, result); err != nil {
+			logger.Printf("configure OS: %v", err)
+		}
+	}
+	if next != nil {
+		reportResult(logger, output, next)
+		eip.NotifyChange(ctx, logger, webhook, eip.BindChange(next, eip.ChangeReasonHandoff, time.Now()))
+	} else {
+		logger.Printf("Done – %s %s released from ENI %s", result.Family, result.TargetIP, result.NetworkInterfaceID)
+		writeReport(logger, output, os.Stdout, &eip.OutputReport{Released: result})
+		if released {
+			eip.NotifyChange(ctx, logger, webhook, eip.UnbindChange(result, eip.ChangeReasonRelease, time.Now()))
+		}
+	}
+}
+
+// reportResult logs result and, with -output json, prints it on stdout.
+func reportResult(logger *log.Logger, output eip.OutputFormat, result *eip.BindResult) {
+	logResult(logger, result)
+	writeReport(logger, output, os.Stdout, &eip.OutputReport{Result: result})
+}
+
+// reportError logs err and its AWS details and, with -output json, prints
+// it on stdout.
+func reportError(logger *log.Logger, output eip.OutputFormat, err error) {
+	logger.Printf("%v", err)
+	reportErrorDetails(logger, output, err)
+}
+
+// reportErrorDetails logs the fields of the APICallError err wraps, if
+// any, and with -output json prints err on stdout.
+func reportErrorDetails(logger *log.Logger, output eip.OutputFormat, err error) {
+	if apiErr, ok := errors.AsType[*eip.APICallError](err); ok {
+		logger.Printf("AWS error: %s", apiErr.Details())
 	}
+	writeReport(logger, output, os.Stdout, &eip.OutputReport{Error: eip.NewErrorDetails(err)})
+}
+
+// writeReport writes report to w as a JSON line in -output json mode.
+func writeReport(logger *log.Logger, output eip.OutputFormat, w io.Writer, report *eip.OutputReport) {
+	if output != eip.OutputJSON {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Printf("output: %v", err)
+	}
+}
 
+func logResult(logger *log.Logger, result *eip.BindResult) {
 	if result.AlreadyAssociated {
 		logger.Printf("No changes needed – %s %s already on instance %s", result.Family, result.TargetIP, result.InstanceID)
+	} else if result.Prefix {
+		logger.Printf("Done – IPv6 prefix %s on ENI %s for instance %s", result.TargetIP, result.NetworkInterfaceID, result.InstanceID)
 	} else if result.Family == eip.IPFamilyIPv6 {
-		logger.Printf("Done – IPv6 %s on ENI %s for instance %s", result.TargetIP, result.NetworkInterfaceID, result.InstanceID)
+		logger.Printf("Done – IPv6 %s on ENI %s for instance %s (primary=%t)", result.TargetIP, result.NetworkInterfaceID, result.InstanceID, result.PrimaryIPv6)
 	} else {
 		logger.Printf("Done – association %s on instance %s", result.AssociationID, result.InstanceID)
 	}
 }
 
-func awsLoadOptionsForConfig(cfg *eip.Config) []func(*config.LoadOptions) error {
-	if cfg.Family != eip.IPFamilyIPv6 {
+// detectNetworkStack replaces an "auto" network stack with the detected one.
+func detectNetworkStack(logger *log.Logger, cfg *eip.Config) {
+	if cfg.NetworkStack != eip.NetworkStackAuto {
+		return
+	}
+	stack, err := eip.DetectNetworkStack()
+	if err != nil {
+		logger.Fatalf("config: %v", err)
+	}
+	cfg.NetworkStack = stack
+	logger.Printf("Detected %s network stack", cfg.NetworkStack)
+}
+
+// runCheck exercises the permissions the configuration in args needs without
+// binding anything, prints a pass/fail table and a least-privilege policy,
+// and returns the exit code.
+func runCheck(ctx context.Context, logger *log.Logger, args []string) int {
+	cfg, err := eip.ParseConfig(args, os.Getenv)
+	if err != nil {
+		logger.Fatalf("config: %v", err)
+	}
+	detectNetworkStack(logger, cfg)
+
+	awsCfg, err := loadAWSConfig(ctx, logger, cfg)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+	imds := ec2imds.NewFromConfig(awsCfg, imdsClientOptionsForConfig(cfg)...)
+	sessionInstanceID, err := roleSessionInstanceID(ctx, cfg, imds, cfg.Role, cfg.AddressRole)
+	if err != nil {
+		logger.Printf("assume role: %v", err)
+	}
+	checker := &eip.Checker{
+		EC2:  ec2ClientForRole(awsCfg, cfg, cfg.Role, sessionInstanceID),
+		IMDS: imds,
+	}
+	if cfg.AddressRole != nil {
+		checker.AddressEC2 = ec2ClientForRole(awsCfg, cfg, cfg.AddressRole, sessionInstanceID)
+	}
+	if cfg.Instance != nil {
+		checker.InstanceID = cfg.Instance.InstanceID
+	}
+
+	results := checker.Check(ctx, cfg)
+	if err := eip.WriteCheckTable(os.Stdout, results); err != nil {
+		logger.Fatalf("check: %v", err)
+	}
+	if err := writeCheckPolicies(os.Stdout, cfg); err != nil {
+		logger.Fatalf("check: %v", err)
+	}
+	if !eip.CheckPassed(results) {
+		return 1
+	}
+	return 0
+}
+
+// writeCheckPolicies prints the least-privilege policy for cfg, split in two
+// when Elastic IP calls use -address-role-arn.
+func writeCheckPolicies(w io.Writer, cfg *eip.Config) error {
+	perms := eip.RequiredPermissions(cfg)
+	titles := []string{"Least-privilege policy"}
+	groups := [][]eip.Permission{perms}
+	if cfg.AddressRole != nil {
+		var ec2Perms, addressPerms []eip.Permission
+		for _, perm := range perms {
+			if perm.Address {
+				addressPerms = append(addressPerms, perm)
+			} else {
+				ec2Perms = append(ec2Perms, perm)
+			}
+		}
+		titles = append(titles, "Least-privilege policy for "+cfg.AddressRole.RoleARN)
+		groups = [][]eip.Permission{ec2Perms, addressPerms}
+	}
+	for i, group := range groups {
+		policy, err := eip.LeastPrivilegePolicy(group).JSON()
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "\n%s:\n%s\n", titles[i], policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPolicy prints the scoped IAM policy for the configuration in args. It
+// makes no AWS calls.
+func runPolicy(logger *log.Logger, args []string) {
+	scope, cfg, err := eip.ParsePolicyConfig(args, os.Getenv)
+	if err != nil {
+		logger.Fatalf("config: %v", err)
+	}
+	policy, err := eip.ScopedPolicy(cfg, scope).JSON()
+	if err != nil {
+		logger.Fatalf("policy: %v", err)
+	}
+	fmt.Println(policy)
+}
+
+// runController binds the annotated pods scheduled on this node until ctx is
+// cancelled.
+func runController(ctx context.Context, logger *log.Logger, args []string) {
+	cfg, err := kube.ParseControllerConfig(args, os.Getenv)
+	if err != nil {
+		logger.Fatalf("config: %v", err)
+	}
+
+	detectNetworkStack(logger, &cfg.AWS)
+	awsCfg, err := loadAWSConfig(ctx, logger, &cfg.AWS)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+
+	client, err := kube.NewInClusterClient()
+	if err != nil {
+		logger.Fatalf("kubernetes: %v", err)
+	}
+
+	binder, err := newBinder(ctx, logger, awsCfg, &cfg.AWS)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+	if cfg.Audit != nil {
+		audit, err := eip.OpenFileAuditSink(cfg.Audit.Path, cfg.Audit.MaxBytes, cfg.Audit.MaxBackups)
+		if err != nil {
+			logger.Fatalf("config: %v", err)
+		}
+		defer audit.Close()
+		binder.Audit = audit
+	}
+	controller := kube.NewController(client, cfg.NodeName, binder, logger)
+	controller.Annotation = cfg.Annotation
+	controller.Webhook = cfg.Webhook
+	if err := controller.Run(ctx); err != nil {
+		logger.Fatalf("controller: %v", err)
+	}
+}
+
+// runLambda serves EventBridge Auto Scaling lifecycle and instance
+// state-change events as an AWS Lambda function.
+func runLambda(ctx context.Context, logger *log.Logger) {
+	cfg, err := lifecycle.ParseHandlerConfig(os.Getenv)
+	if err != nil {
+		logger.Fatalf("config: %v", err)
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		logger.Fatalf("loading AWS config: %v", err)
+	}
+
+	handler := lifecycle.NewHandler(ec2.NewFromConfig(awsCfg), autoscaling.NewFromConfig(awsCfg), logger)
+	handler.TagKey = cfg.TagKey
+	handler.FailureResult = cfg.FailureResult
+	handler.Webhook = cfg.Webhook
+	lambda.StartWithOptions(handler.Handle, lambda.WithContext(ctx))
+}
+
+// loadAWSConfig loads the SDK configuration for cfg. When no region is
+// configured it falls back to the instance's region from instance metadata.
+func loadAWSConfig(ctx context.Context, logger *log.Logger, cfg *eip.Config) (aws.Config, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx, awsLoadOptionsForConfig(cfg)...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("loading AWS config: %w", err)
+	}
+	imds := ec2imds.NewFromConfig(awsCfg, imdsClientOptionsForConfig(cfg)...)
+	region, source, err := eip.ResolveRegion(ctx, awsCfg.Region, imds)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("loading AWS config: %w", err)
+	}
+	awsCfg.Region = region
+	logger.Printf("AWS configuration loaded (region=%s from %s)", awsCfg.Region, source)
+	return awsCfg, nil
+}
+
+// newBinder creates a Binder whose EC2 and instance metadata clients follow
+// cfg's roles, endpoints, and IMDS options.
+func newBinder(ctx context.Context, logger *log.Logger, awsCfg aws.Config, cfg *eip.Config) (*eip.Binder, error) {
+	imds := ec2imds.NewFromConfig(awsCfg, imdsClientOptionsForConfig(cfg)...)
+	sessionInstanceID, err := roleSessionInstanceID(ctx, cfg, imds, cfg.Role, cfg.AddressRole)
+	if err != nil {
+		return nil, fmt.Errorf("assume role: %w", err)
+	}
+	ec2Client := ec2ClientForRole(awsCfg, cfg, cfg.Role, sessionInstanceID)
+	addressClient := ec2Client
+	if cfg.AddressRole != nil {
+		addressClient = ec2ClientForRole(awsCfg, cfg, cfg.AddressRole, sessionInstanceID)
+		logger.Printf("Using role %s for Elastic IP calls", cfg.AddressRole.RoleARN)
+	}
+	if cfg.Role != nil {
+		logger.Printf("Using role %s for EC2 calls", cfg.Role.RoleARN)
+	}
+	binder := eip.NewBinder(ec2Client, imds, logger)
+	binder.AddressEC2 = addressClient
+	return binder, nil
+}
+
+// awsConfigForRole returns awsCfg with credentials that assume -role-arn, so
+// the SSM, Secrets Manager, and Route 53 clients act as the same principal as
+// the EC2 calls. It returns awsCfg unchanged when no role is configured.
+func awsConfigForRole(ctx context.Context, awsCfg aws.Config, cfg *eip.Config) (aws.Config, error) {
+	if cfg.Role == nil {
+		return awsCfg, nil
+	}
+	imds := ec2imds.NewFromConfig(awsCfg, imdsClientOptionsForConfig(cfg)...)
+	sessionInstanceID, err := roleSessionInstanceID(ctx, cfg, imds, cfg.Role)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("assume role: %w", err)
+	}
+	roleCfg := awsCfg.Copy()
+	roleCfg.Credentials = aws.NewCredentialsCache(assumeRoleProvider(awsCfg, cfg.Role, sessionInstanceID))
+	return roleCfg, nil
+}
+
+// roleSessionInstanceID returns the instance ID that the session names of
+// roles are derived from. It is only looked up when one of roles has no
+// explicit session name; off-instance tag selectors yield no ID.
+func roleSessionInstanceID(ctx context.Context, cfg *eip.Config, imds eip.MetadataClient, roles ...*eip.AssumeRole) (string, error) {
+	needed := false
+	for _, role := range roles {
+		if role != nil && role.SessionName == "" {
+			needed = true
+		}
+	}
+	if !needed {
+		return "", nil
+	}
+	if cfg.Instance != nil {
+		return cfg.Instance.InstanceID, nil
+	}
+	return eip.CurrentInstanceID(ctx, imds)
+}
+
+// ec2ClientForRole returns an EC2 client for cfg whose credentials assume
+// role, or one using the default credentials when role is nil.
+func ec2ClientForRole(awsCfg aws.Config, cfg *eip.Config, role *eip.AssumeRole, instanceID string) *ec2.Client {
+	opts := ec2ClientOptionsForConfig(cfg)
+	if role != nil {
+		provider := assumeRoleProvider(awsCfg, role, instanceID)
+		opts = append(opts, func(o *ec2.Options) {
+			o.Credentials = aws.NewCredentialsCache(provider)
+		})
+	}
+	return ec2.NewFromConfig(awsCfg, opts...)
+}
+
+// assumeRoleProvider returns credentials that assume role with the
+// credentials of awsCfg.
+func assumeRoleProvider(awsCfg aws.Config, role *eip.AssumeRole, instanceID string) *stscreds.AssumeRoleProvider {
+	return stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), role.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = role.SessionNameFor(instanceID)
+		if role.ExternalID != "" {
+			o.ExternalID = aws.String(role.ExternalID)
+		}
+	})
+}
+
+func ec2ClientOptionsForConfig(cfg *eip.Config) []func(*ec2.Options) {
+	var opts []func(*ec2.Options)
+	if cfg.EC2Endpoint != "" {
+		opts = append(opts, func(o *ec2.Options) {
+			o.BaseEndpoint = aws.String(cfg.EC2Endpoint)
+		})
+	}
+	if dualStackEndpointState(cfg) == aws.DualStackEndpointStateEnabled || cfg.EC2FIPS {
+		// A custom endpoint, from -ec2-endpoint or AWS_ENDPOINT_URL_EC2,
+		// already picks the IP stack and FIPS mode, and the SDK refuses to
+		// resolve it as dual-stack or FIPS. -fips still applies to the other
+		// AWS clients.
+		opts = append(opts, func(o *ec2.Options) {
+			if o.BaseEndpoint != nil {
+				o.EndpointOptions.UseDualStackEndpoint = aws.DualStackEndpointStateDisabled
+				o.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateDisabled
+			}
+		})
+	}
+	return opts
+}
+
+// resolveEC2Target resolves an INSTANCE_TAG or address pool target with
+// binder's clients.
+func resolveEC2Target(ctx context.Context, logger *log.Logger, cfg *eip.Config, binder *eip.Binder) error {
+	if cfg.TargetRef == eip.InstanceTagTarget {
+		target, err := eip.ResolveInstanceTag(ctx, binder.EC2, binder.IMDS, binder.InstanceID, cfg.InstanceTag)
+		if err != nil {
+			return err
+		}
+		if err := cfg.SetTarget(target); err != nil {
+			return err
+		}
+		logger.Printf("Resolved instance tag %s: %s", cfg.InstanceTag, cfg.TargetIP)
 		return nil
 	}
-	return []func(*config.LoadOptions) error{
-		config.WithEC2IMDSEndpointMode(ec2imds.EndpointModeStateIPv6),
-		config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled),
+	target, err := eip.ResolveAddressPool(ctx, binder.AddressEC2, cfg.AddressPool)
+	if err != nil {
+		return err
+	}
+	if err := cfg.SetTarget(target); err != nil {
+		return err
+	}
+	logger.Printf("Resolved %s %d from pool %s=%s: %s", eip.PodOrdinalTarget, cfg.AddressPool.Index, cfg.AddressPool.TagKey, cfg.AddressPool.TagValue, cfg.TargetIP)
+	return nil
+}
+
+// reloadAWSConfigForFamily reloads awsCfg once a target resolved at runtime
+// turns out to be IPv6, since -network-stack family picks the IMDS and
+// endpoint stack by the target family. It reports whether it reloaded.
+func reloadAWSConfigForFamily(ctx context.Context, logger *log.Logger, cfg *eip.Config, awsCfg *aws.Config) (bool, error) {
+	if cfg.NetworkStack != eip.NetworkStackFamily || cfg.Family != eip.IPFamilyIPv6 {
+		return false, nil
+	}
+	reloaded, err := loadAWSConfig(ctx, logger, cfg)
+	if err != nil {
+		return false, err
+	}
+	*awsCfg = reloaded
+	return true, nil
+}
+
+// resolvePodAnnotation reads the target from the running pod's own
+// annotation. POD_NAMESPACE and POD_NAME are expected from the downward API.
+func resolvePodAnnotation(ctx context.Context, cfg *eip.Config) error {
+	namespace, name := os.Getenv("POD_NAMESPACE"), os.Getenv("POD_NAME")
+	if namespace == "" || name == "" {
+		return fmt.Errorf("%s requires the POD_NAMESPACE and POD_NAME environment variables", eip.PodAnnotationTarget)
+	}
+	client, err := kube.NewInClusterClient()
+	if err != nil {
+		return err
+	}
+	value, err := kube.PodAddress(ctx, client, namespace, name, kube.AddressAnnotation)
+	if err != nil {
+		return err
+	}
+	return cfg.SetTarget(value)
+}
+
+func awsLoadOptionsForConfig(cfg *eip.Config) []func(*config.LoadOptions) error {
+	var opts []func(*config.LoadOptions) error
+	if mode := imdsEndpointMode(cfg); mode != ec2imds.EndpointModeStateUnset {
+		opts = append(opts, config.WithEC2IMDSEndpointMode(mode))
+	}
+	if state := dualStackEndpointState(cfg); state != aws.DualStackEndpointStateUnset {
+		opts = append(opts, config.WithUseDualStackEndpoint(state))
+	}
+	if cfg.EC2FIPS {
+		opts = append(opts, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
 	}
+	if cfg.IMDS.Endpoint != "" {
+		opts = append(opts, config.WithEC2IMDSEndpoint(cfg.IMDS.Endpoint))
+	}
+	if cfg.IMDS.DisableV1 || cfg.IMDS.Timeout > 0 || cfg.IMDS.MaxAttempts > 0 {
+		// Instance role credentials are fetched through their own IMDS client,
+		// which only sees these settings when it is built here.
+		opts = append(opts, config.WithEC2RoleCredentialOptions(func(o *ec2rolecreds.Options) {
+			o.Client = ec2imds.New(ec2imds.Options{}, imdsClientOptionsForConfig(cfg)...)
+		}))
+	}
+	return opts
 }
 
 func imdsClientOptionsForConfig(cfg *eip.Config) []func(*ec2imds.Options) {
-	if cfg.Family != eip.IPFamilyIPv6 {
-		return nil
+	var opts []func(*ec2imds.Options)
+	if mode := imdsEndpointMode(cfg); mode != ec2imds.EndpointModeStateUnset {
+		opts = append(opts, func(o *ec2imds.Options) {
+			o.EndpointMode = mode
+		})
+	}
+	if cfg.IMDS.Endpoint != "" {
+		opts = append(opts, func(o *ec2imds.Options) {
+			o.Endpoint = cfg.IMDS.Endpoint
+		})
+	}
+	if cfg.IMDS.DisableV1 {
+		opts = append(opts, func(o *ec2imds.Options) {
+			o.EnableFallback = aws.FalseTernary
+		})
+	}
+	if cfg.IMDS.Timeout > 0 {
+		opts = append(opts, func(o *ec2imds.Options) {
+			o.DisableDefaultTimeout = true
+			o.HTTPClient = awshttp.NewBuildableClient().WithTimeout(cfg.IMDS.Timeout)
+		})
+	}
+	if cfg.IMDS.MaxAttempts > 0 {
+		opts = append(opts, func(o *ec2imds.Options) {
+			o.Retryer = retry.NewStandard(func(so *retry.StandardOptions) {
+				so.MaxAttempts = cfg.IMDS.MaxAttempts
+			})
+		})
+	}
+	return opts
+}
+
+// imdsEndpointMode returns the configured IMDS endpoint mode. Without an
+// explicit mode it follows the network stack, or the target family when no
+// stack is set.
+func imdsEndpointMode(cfg *eip.Config) ec2imds.EndpointModeState {
+	if cfg.IMDS.EndpointMode != ec2imds.EndpointModeStateUnset {
+		return cfg.IMDS.EndpointMode
+	}
+	switch cfg.NetworkStack {
+	case eip.NetworkStackIPv6:
+		return ec2imds.EndpointModeStateIPv6
+	case eip.NetworkStackIPv4, eip.NetworkStackDualStack:
+		return ec2imds.EndpointModeStateIPv4
+	}
+	if cfg.Family == eip.IPFamilyIPv6 {
+		return ec2imds.EndpointModeStateIPv6
+	}
+	return ec2imds.EndpointModeStateUnset
+}
+
+// dualStackEndpointState returns whether AWS service endpoints should be
+// dual-stack. EC2 has no IPv6-only endpoints, so an IPv6 stack uses the
+// dual-stack ones.
+func dualStackEndpointState(cfg *eip.Config) aws.DualStackEndpointState {
+	switch cfg.NetworkStack {
+	case eip.NetworkStackIPv6, eip.NetworkStackDualStack:
+		return aws.DualStackEndpointStateEnabled
+	case eip.NetworkStackIPv4:
+		return aws.DualStackEndpointStateDisabled
 	}
-	return []func(*ec2imds.Options){
-		func(o *ec2imds.Options) {
-			o.EndpointMode = ec2imds.EndpointModeStateIPv6
-		},
+	if cfg.Family == eip.IPFamilyIPv6 {
+		return aws.DualStackEndpointStateEnabled
 	}
+	return aws.DualStackEndpointStateUnset
 }
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"io"
+	"log"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	ec2imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
 
 	"github.com/islishude/aws-eip-binding/eip"
 )
@@ -17,6 +24,9 @@ func TestAWSLoadOptionsForConfig(t *testing.T) {
 		wantOptionLen int
 		wantIMDSMode  ec2imds.EndpointModeState
 		wantDualStack aws.DualStackEndpointState
+		wantFIPS      aws.FIPSEndpointState
+		wantEndpoint  string
+		wantRoleCreds bool
 	}{
 		{
 			name: "IPv4 uses default SDK behavior",
@@ -29,6 +39,46 @@ func TestAWSLoadOptionsForConfig(t *testing.T) {
 			wantIMDSMode:  ec2imds.EndpointModeStateIPv6,
 			wantDualStack: aws.DualStackEndpointStateEnabled,
 		},
+		{
+			name:          "explicit IMDS endpoint mode is independent of family",
+			cfg:           eip.Config{Family: eip.IPFamilyIPv4, IMDS: eip.IMDSOptions{EndpointMode: ec2imds.EndpointModeStateIPv6}},
+			wantOptionLen: 1,
+			wantIMDSMode:  ec2imds.EndpointModeStateIPv6,
+		},
+		{
+			name:          "IPv4 stack overrides IPv6 family",
+			cfg:           eip.Config{Family: eip.IPFamilyIPv6, NetworkStack: eip.NetworkStackIPv4},
+			wantOptionLen: 2,
+			wantIMDSMode:  ec2imds.EndpointModeStateIPv4,
+			wantDualStack: aws.DualStackEndpointStateDisabled,
+		},
+		{
+			name:          "dual-stack keeps IPv4 IMDS for IPv4 targets",
+			cfg:           eip.Config{Family: eip.IPFamilyIPv4, NetworkStack: eip.NetworkStackDualStack},
+			wantOptionLen: 2,
+			wantIMDSMode:  ec2imds.EndpointModeStateIPv4,
+			wantDualStack: aws.DualStackEndpointStateEnabled,
+		},
+		{
+			name:          "explicit IMDS mode wins over network stack",
+			cfg:           eip.Config{Family: eip.IPFamilyIPv4, NetworkStack: eip.NetworkStackIPv6, IMDS: eip.IMDSOptions{EndpointMode: ec2imds.EndpointModeStateIPv4}},
+			wantOptionLen: 2,
+			wantIMDSMode:  ec2imds.EndpointModeStateIPv4,
+			wantDualStack: aws.DualStackEndpointStateEnabled,
+		},
+		{
+			name:          "FIPS endpoints",
+			cfg:           eip.Config{Family: eip.IPFamilyIPv4, EC2FIPS: true},
+			wantOptionLen: 1,
+			wantFIPS:      aws.FIPSEndpointStateEnabled,
+		},
+		{
+			name:          "IMDS client settings reach role credentials",
+			cfg:           eip.Config{Family: eip.IPFamilyIPv4, IMDS: eip.IMDSOptions{Endpoint: "http://127.0.0.1:1338", DisableV1: true}},
+			wantOptionLen: 2,
+			wantEndpoint:  "http://127.0.0.1:1338",
+			wantRoleCreds: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -48,6 +98,15 @@ func TestAWSLoadOptionsForConfig(t *testing.T) {
 			if loadOptions.UseDualStackEndpoint != tt.wantDualStack {
 				t.Errorf("UseDualStackEndpoint = %v, want %v", loadOptions.UseDualStackEndpoint, tt.wantDualStack)
 			}
+			if loadOptions.UseFIPSEndpoint != tt.wantFIPS {
+				t.Errorf("UseFIPSEndpoint = %v, want %v", loadOptions.UseFIPSEndpoint, tt.wantFIPS)
+			}
+			if loadOptions.EC2IMDSEndpoint != tt.wantEndpoint {
+				t.Errorf("EC2IMDSEndpoint = %q, want %q", loadOptions.EC2IMDSEndpoint, tt.wantEndpoint)
+			}
+			if (loadOptions.EC2RoleCredentialOptions != nil) != tt.wantRoleCreds {
+				t.Errorf("EC2RoleCredentialOptions set = %t, want %t", loadOptions.EC2RoleCredentialOptions != nil, tt.wantRoleCreds)
+			}
 		})
 	}
 }
@@ -58,6 +117,9 @@ func TestIMDSClientOptionsForConfig(t *testing.T) {
 		cfg              eip.Config
 		wantOptionLen    int
 		wantEndpointMode ec2imds.EndpointModeState
+		wantEndpoint     string
+		wantNoFallback   bool
+		wantMaxAttempts  int
 	}{
 		{
 			name:          "IPv4 uses default SDK IMDS options",
@@ -70,6 +132,21 @@ func TestIMDSClientOptionsForConfig(t *testing.T) {
 			wantOptionLen:    1,
 			wantEndpointMode: ec2imds.EndpointModeStateIPv6,
 		},
+		{
+			name: "explicit IMDS settings",
+			cfg: eip.Config{Family: eip.IPFamilyIPv6, IMDS: eip.IMDSOptions{
+				Endpoint:     "http://127.0.0.1:1338",
+				EndpointMode: ec2imds.EndpointModeStateIPv4,
+				DisableV1:    true,
+				Timeout:      2 * time.Second,
+				MaxAttempts:  5,
+			}},
+			wantOptionLen:    5,
+			wantEndpointMode: ec2imds.EndpointModeStateIPv4,
+			wantEndpoint:     "http://127.0.0.1:1338",
+			wantNoFallback:   true,
+			wantMaxAttempts:  5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -83,10 +160,50 @@ func TestIMDSClientOptionsForConfig(t *testing.T) {
 			if imdsOptions.EndpointMode != tt.wantEndpointMode {
 				t.Errorf("EndpointMode = %v, want %v", imdsOptions.EndpointMode, tt.wantEndpointMode)
 			}
+			if imdsOptions.Endpoint != tt.wantEndpoint {
+				t.Errorf("Endpoint = %q, want %q", imdsOptions.Endpoint, tt.wantEndpoint)
+			}
+			if (imdsOptions.EnableFallback == aws.FalseTernary) != tt.wantNoFallback {
+				t.Errorf("EnableFallback = %v, want disabled=%t", imdsOptions.EnableFallback, tt.wantNoFallback)
+			}
+			if tt.wantMaxAttempts != 0 && (imdsOptions.Retryer == nil || imdsOptions.Retryer.MaxAttempts() != tt.wantMaxAttempts) {
+				t.Errorf("Retryer max attempts != %d", tt.wantMaxAttempts)
+			}
 		})
 	}
 }
 
+func TestEC2ClientOptionsForConfig(t *testing.T) {
+	if opts := ec2ClientOptionsForConfig(&eip.Config{}); len(opts) != 0 {
+		t.Fatalf("EC2 option count = %d, want 0", len(opts))
+	}
+
+	opts := ec2ClientOptionsForConfig(&eip.Config{EC2Endpoint: "https://vpce-0123.ec2.us-east-1.vpce.amazonaws.com"})
+	var ec2Options ec2.Options
+	for _, opt := range opts {
+		opt(&ec2Options)
+	}
+	if ec2Options.BaseEndpoint == nil || *ec2Options.BaseEndpoint != "https://vpce-0123.ec2.us-east-1.vpce.amazonaws.com" {
+		t.Fatalf("BaseEndpoint = %v, want the VPC endpoint", ec2Options.BaseEndpoint)
+	}
+
+	ec2Options = ec2.Options{EndpointOptions: ec2.EndpointResolverOptions{UseDualStackEndpoint: aws.DualStackEndpointStateEnabled}}
+	for _, opt := range ec2ClientOptionsForConfig(&eip.Config{Family: eip.IPFamilyIPv6, EC2Endpoint: "http://127.0.0.1:8080"}) {
+		opt(&ec2Options)
+	}
+	if ec2Options.EndpointOptions.UseDualStackEndpoint != aws.DualStackEndpointStateDisabled {
+		t.Fatalf("UseDualStackEndpoint = %v, want disabled with a custom endpoint", ec2Options.EndpointOptions.UseDualStackEndpoint)
+	}
+
+	ec2Options = ec2.Options{EndpointOptions: ec2.EndpointResolverOptions{UseFIPSEndpoint: aws.FIPSEndpointStateEnabled}}
+	for _, opt := range ec2ClientOptionsForConfig(&eip.Config{Family: eip.IPFamilyIPv4, EC2Endpoint: "http://127.0.0.1:8080", EC2FIPS: true}) {
+		opt(&ec2Options)
+	}
+	if ec2Options.EndpointOptions.UseFIPSEndpoint != aws.FIPSEndpointStateDisabled {
+		t.Fatalf("UseFIPSEndpoint = %v, want disabled with a custom endpoint", ec2Options.EndpointOptions.UseFIPSEndpoint)
+	}
+}
+
 func applyLoadOptions(t *testing.T, opts []func(*config.LoadOptions) error) config.LoadOptions {
 	t.Helper()
 
@@ -106,3 +223,141 @@ func applyIMDSClientOptions(opts []func(*ec2imds.Options)) ec2imds.Options {
 	}
 	return imdsOptions
 }
+
+func TestRoleSessionInstanceID(t *testing.T) {
+	role := &eip.AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/eip-binding"}
+	tests := []struct {
+		name string
+		cfg  eip.Config
+		want string
+	}{
+		{name: "no role", cfg: eip.Config{}},
+		{name: "explicit session name", cfg: eip.Config{Role: &eip.AssumeRole{RoleARN: role.RoleARN, SessionName: "failover"}}},
+		{
+			name: "off-instance by ID",
+			cfg:  eip.Config{AddressRole: role, Instance: &eip.InstanceSelector{InstanceID: "i-0123456789abcdef0"}},
+			want: "i-0123456789abcdef0",
+		},
+		{
+			name: "off-instance by tag",
+			cfg:  eip.Config{Role: role, Instance: &eip.InstanceSelector{TagKey: "Name", TagValue: "web"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A nil metadata client panics if instance metadata is consulted.
+			got, err := roleSessionInstanceID(context.Background(), &tt.cfg, nil, tt.cfg.Role, tt.cfg.AddressRole)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("instance ID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSConfigForRole(t *testing.T) {
+	base := aws.Config{Region: "us-west-2", Credentials: aws.AnonymousCredentials{}}
+
+	got, err := awsConfigForRole(context.Background(), base, &eip.Config{
+		AddressRole: &eip.AssumeRole{RoleARN: "arn:aws:iam::222222222222:role/eip-addresses"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := got.Credentials.(aws.AnonymousCredentials); !ok {
+		t.Fatalf("credentials = %T, want the base credentials without -role-arn", got.Credentials)
+	}
+
+	got, err = awsConfigForRole(context.Background(), base, &eip.Config{
+		Role: &eip.AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/eip-binding", SessionName: "failover"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache, ok := got.Credentials.(*aws.CredentialsCache)
+	if !ok {
+		t.Fatalf("credentials = %T, want a cache of assumed-role credentials", got.Credentials)
+	}
+	if !cache.IsCredentialsProvider(&stscreds.AssumeRoleProvider{}) {
+		t.Fatal("credentials do not assume -role-arn")
+	}
+	if _, ok := base.Credentials.(aws.AnonymousCredentials); !ok {
+		t.Fatalf("base credentials changed to %T", base.Credentials)
+	}
+}
+
+func TestReloadAWSConfigForFamily(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-west-2")
+	logger := log.New(io.Discard, "", 0)
+	tests := []struct {
+		name         string
+		cfg          eip.Config
+		wantReloaded bool
+	}{
+		{name: "IPv4 target", cfg: eip.Config{Family: eip.IPFamilyIPv4, NetworkStack: eip.NetworkStackFamily}},
+		{name: "IPv6 target", cfg: eip.Config{Family: eip.IPFamilyIPv6, NetworkStack: eip.NetworkStackFamily}, wantReloaded: true},
+		{name: "explicit stack", cfg: eip.Config{Family: eip.IPFamilyIPv6, NetworkStack: eip.NetworkStackIPv4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			awsCfg := aws.Config{Region: "unchanged"}
+			reloaded, err := reloadAWSConfigForFamily(context.Background(), logger, &tt.cfg, &awsCfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reloaded != tt.wantReloaded {
+				t.Fatalf("reloaded = %v, want %v", reloaded, tt.wantReloaded)
+			}
+			wantRegion := "unchanged"
+			if tt.wantReloaded {
+				wantRegion = "us-west-2"
+			}
+			if awsCfg.Region != wantRegion {
+				t.Fatalf("region = %q, want %q", awsCfg.Region, wantRegion)
+			}
+		})
+	}
+}
+
+func TestWriteCheckPolicies(t *testing.T) {
+	cfg := &eip.Config{
+		TargetIP:    "54.162.153.80",
+		Family:      eip.IPFamilyIPv4,
+		AddressRole: &eip.AssumeRole{RoleARN: "arn:aws:iam::222222222222:role/eip-addresses"},
+	}
+	var out strings.Builder
+	if err := writeCheckPolicies(&out, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ec2Policy, addressPolicy, ok := strings.Cut(out.String(), "Least-privilege policy for arn:aws:iam::222222222222:role/eip-addresses:")
+	if !ok {
+		t.Fatalf("no address role policy in:\n%s", out.String())
+	}
+	if !strings.Contains(ec2Policy, "ec2:DescribeNetworkInterfaces") || strings.Contains(ec2Policy, "ec2:AssociateAddress") {
+		t.Errorf("EC2 policy has the wrong actions:\n%s", ec2Policy)
+	}
+	if !strings.Contains(addressPolicy, "ec2:AssociateAddress") || strings.Contains(addressPolicy, "ec2:DescribeNetworkInterfaces") {
+		t.Errorf("address policy has the wrong actions:\n%s", addressPolicy)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	report := &eip.OutputReport{Error: &eip.ErrorDetails{Message: "bind: throttled"}}
+	logger := log.New(io.Discard, "", 0)
+
+	var text strings.Builder
+	writeReport(logger, eip.OutputText, &text, report)
+	if text.Len() != 0 {
+		t.Fatalf("text output wrote %q to stdout", text.String())
+	}
+
+	var out strings.Builder
+	writeReport(logger, eip.OutputJSON, &out, report)
+	if got, want := out.String(), "{\"error\":{\"message\":\"bind: throttled\"}}\n"; got != want {
+		t.Fatalf("JSON output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package fakeaws
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+var (
+	buildOnce sync.Once
+	buildDir  string
+	binary    string
+	buildErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if buildDir != "" {
+		os.RemoveAll(buildDir)
+	}
+	os.Exit(code)
+}
+
+// buildBinary builds aws-eip-binding once per test run.
+func buildBinary(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds the binary")
+	}
+	buildOnce.Do(func() {
+		buildDir, buildErr = os.MkdirTemp("", "fakeaws")
+		if buildErr != nil {
+			return
+		}
+		binary = filepath.Join(buildDir, "aws-eip-binding")
+		out, err := exec.Command("go", "build", "-o", binary, "github.com/islishude/aws-eip-binding").CombinedOutput()
+		if err != nil {
+			buildErr = &buildError{err: err, output: out}
+		}
+	})
+	if buildErr != nil {
+		t.Fatalf("build: %v", buildErr)
+	}
+	return binary
+}
+
+type buildError struct {
+	err    error
+	output []byte
+}
+
+func (e *buildError) Error() string {
+	return e.err.Error() + "\n" + string(e.output)
+}
+
+// runBinary runs the binary against server with -output json and returns
+// its reports and exit code.
+func runBinary(t *testing.T, server *Server, args ...string) ([]eip.OutputReport, int) {
+	t.Helper()
+	bin := buildBinary(t)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	cmd := exec.Command(bin, append([]string{"-output", "json"}, args...)...)
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + t.TempDir(),
+		"AWS_REGION=us-east-1",
+		"AWS_ACCESS_KEY_ID=fake",
+		"AWS_SECRET_ACCESS_KEY=fake",
+		"AWS_ENDPOINT_URL_EC2=" + httpServer.URL,
+		"AWS_EC2_METADATA_SERVICE_ENDPOINT=" + httpServer.URL,
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	code := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	t.Logf("stderr:\n%s", stderr.String())
+
+	var reports []eip.OutputReport
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		var report eip.OutputReport
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			t.Fatalf("decode stdout line %q: %v", scanner.Text(), err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, code
+}
+
+func TestBinaryBindsIPv4(t *testing.T) {
+	server := NewServer(testModel())
+	reports, code := runBinary(t, server, "54.162.153.80")
+	if code != 0 || len(reports) != 1 || reports[0].Result == nil {
+		t.Fatalf("exit %d, reports %s, want one result", code, formatReports(reports))
+	}
+	result := reports[0].Result
+	if result.NetworkInterfaceID != "eni-local" || result.PreviousInstanceID != "i-other" {
+		t.Fatalf("result = %+v, want move from i-other to eni-local", result)
+	}
+	if address := server.Model().Address("54.162.153.80"); address.AssociationID != result.AssociationID {
+		t.Fatalf("address = %+v, want association %s", address, result.AssociationID)
+	}
+}
+
+func TestBinaryReleasesOnSpotInterruption(t *testing.T) {
+	model := testModel()
+	model.Metadata = map[string]string{"spot/instance-action": `{"action":"terminate","time":"2026-01-01T00:00:00Z"}`}
+	server := NewServer(model)
+	reports, code := runBinary(t, server, "-on-interruption", "unbind", "-interruption-interval", "10ms", "2001:db8::10")
+	if code != 0 || len(reports) != 2 || reports[0].Result == nil || reports[1].Released == nil {
+		t.Fatalf("exit %d, reports %s, want a result then a release", code, formatReports(reports))
+	}
+	for _, eni := range server.Model().NetworkInterfaces {
+		if len(eni.IPv6Addresses) != 0 {
+			t.Fatalf("%s IPv6 = %v, want the address released", eni.ID, eni.IPv6Addresses)
+		}
+	}
+}
+
+func TestBinaryReportsAPIError(t *testing.T) {
+	reports, code := runBinary(t, NewServer(testModel()), "192.0.2.1")
+	if code != 1 || len(reports) != 1 || reports[0].Error == nil {
+		t.Fatalf("exit %d, reports %s, want one API error", code, formatReports(reports))
+	}
+	apiErr := reports[0].Error
+	if apiErr.Operation != "DescribeAddresses" || apiErr.Code != "InvalidAddress.NotFound" || apiErr.HTTPStatus != 400 || apiErr.RequestID == "" {
+		t.Fatalf("error = %+v, want DescribeAddresses InvalidAddress.NotFound with a request ID", apiErr)
+	}
+}
+
+// formatReports renders reports for failure messages.
+func formatReports(reports []eip.OutputReport) string {
+	data, _ := json.Marshal(reports)
+	return string(data)
+}
@@ -0,0 +1,43 @@
+// Command fakeaws serves the fake EC2 API and instance metadata service from
+// a JSON state file, for running the binary in scenario scripts:
+//
+//	go run ./test/fakeaws/cmd/fakeaws -state state.json -listen 127.0.0.1:8080
+//	AWS_ENDPOINT_URL_EC2=http://127.0.0.1:8080 \
+//	AWS_EC2_METADATA_SERVICE_ENDPOINT=http://127.0.0.1:8080 \
+//	AWS_ACCESS_KEY_ID=fake AWS_SECRET_ACCESS_KEY=fake AWS_REGION=us-east-1 \
+//	go run . 54.162.153.80
+//	curl http://127.0.0.1:8080/_fakeaws/state
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/islishude/aws-eip-binding/test/fakeaws"
+)
+
+func main() {
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	listen := flag.String("listen", "127.0.0.1:8080", "address to listen on")
+	state := flag.String("state", "", "JSON file with the initial fakeaws.Model")
+	flag.Parse()
+
+	model := &fakeaws.Model{}
+	if *state != "" {
+		var err error
+		model, err = fakeaws.LoadModel(*state)
+		if err != nil {
+			logger.Fatalf("state: %v", err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		logger.Fatalf("listen: %v", err)
+	}
+	logger.Printf("Serving fake EC2 and instance metadata on http://%s", listener.Addr())
+	logger.Fatal(http.Serve(listener, fakeaws.NewServer(model)))
+}
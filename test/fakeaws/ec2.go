@@ -0,0 +1,839 @@
+package fakeaws
+
+import (
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const ec2Namespace = "http://ec2.amazonaws.com/doc/2016-11-15/"
+
+// apiError is an EC2 error response.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+func errorf(code, format string, args ...any) *apiError {
+	return &apiError{status: http.StatusBadRequest, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// ec2Action handles one EC2 action with the model locked.
+type ec2Action func(s *Server, q query) (responder, error)
+
+// responder is an action's response document. Each embeds response so
+// serveEC2 can fill in the request ID.
+type responder interface {
+	setRequestID(id string)
+}
+
+type response struct {
+	RequestID string `xml:"requestId"`
+}
+
+func (r *response) setRequestID(id string) {
+	r.RequestID = id
+}
+
+var ec2Actions = map[string]ec2Action{
+	"DescribeAddresses":               (*Server).describeAddresses,
+	"DescribeNetworkInterfaces":       (*Server).describeNetworkInterfaces,
+	"AssociateAddress":                (*Server).associateAddress,
+	"DisassociateAddress":             (*Server).disassociateAddress,
+	"AssignIpv6Addresses":             (*Server).assignIPv6Addresses,
+	"UnassignIpv6Addresses":           (*Server).unassignIPv6Addresses,
+	"ModifyNetworkInterfaceAttribute": (*Server).modifyNetworkInterfaceAttribute,
+	"DescribeInstances":               (*Server).describeInstances,
+	"DescribeSubnets":                 (*Server).describeSubnets,
+	"DescribeTags":                    (*Server).describeTags,
+}
+
+// serveEC2 handles an EC2 Query API request: a form-encoded POST naming the
+// Action, answered with an XML document.
+func (s *Server) serveEC2(w http.ResponseWriter, r *http.Request) {
+	requestID := s.nextRequestID()
+	w.Header().Set("x-amzn-RequestId", requestID)
+	if err := r.ParseForm(); err != nil {
+		writeEC2Error(w, requestID, errorf("MalformedQueryString", "%v", err))
+		return
+	}
+	q := query(r.Form)
+	action, ok := ec2Actions[q.get("Action")]
+	if !ok {
+		writeEC2Error(w, requestID, errorf("InvalidAction", "The action %s is not valid for this web service.", q.get("Action")))
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, q.get("Action"))
+	var body responder
+	var err error
+	if q.get("DryRun") == "true" {
+		err = &apiError{status: http.StatusPreconditionFailed, code: "DryRunOperation", message: "Request would have succeeded, but DryRun flag is set."}
+	} else {
+		body, err = action(s, q)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		apiErr, ok := err.(*apiError)
+		if !ok {
+			apiErr = &apiError{status: http.StatusInternalServerError, code: "InternalError", message: err.Error()}
+		}
+		writeEC2Error(w, requestID, apiErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	enc := xml.NewEncoder(w)
+	start := xml.StartElement{
+		Name: xml.Name{Local: q.get("Action") + "Response"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: ec2Namespace}},
+	}
+	body.setRequestID(requestID)
+	_ = enc.EncodeElement(body, start)
+}
+
+func writeEC2Error(w http.ResponseWriter, requestID string, err *apiError) {
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	w.WriteHeader(err.status)
+	body := struct {
+		XMLName   xml.Name `xml:"Response"`
+		Code      string   `xml:"Errors>Error>Code"`
+		Message   string   `xml:"Errors>Error>Message"`
+		RequestID string   `xml:"RequestID"`
+	}{Code: err.code, Message: err.message, RequestID: requestID}
+	_ = xml.NewEncoder(w).Encode(&body)
+}
+
+// query is a parsed EC2 Query API request.
+type query url.Values
+
+func (q query) get(key string) string {
+	return url.Values(q).Get(key)
+}
+
+// list returns the values of a flattened list parameter: KEY.1, KEY.2, ...
+func (q query) list(key string) []string {
+	var values []string
+	for i := 1; ; i++ {
+		value, ok := q[key+"."+strconv.Itoa(i)]
+		if !ok {
+			return values
+		}
+		values = append(values, value...)
+	}
+}
+
+// filter is one Filter.N parameter.
+type filter struct {
+	name   string
+	values []string
+}
+
+func (q query) filters() []filter {
+	var filters []filter
+	for i := 1; ; i++ {
+		prefix := "Filter." + strconv.Itoa(i)
+		name := q.get(prefix + ".Name")
+		if name == "" {
+			return filters
+		}
+		filters = append(filters, filter{name: name, values: q.list(prefix + ".Value")})
+	}
+}
+
+// matchFilters reports whether a resource matches every filter. values
+// returns the resource's values for a filter name, and false for filter
+// names the resource does not support.
+func matchFilters(filters []filter, values func(name string) ([]string, bool)) (bool, error) {
+	for _, f := range filters {
+		have, ok := values(f.name)
+		if !ok {
+			return false, errorf("InvalidParameterValue", "The filter '%s' is invalid", f.name)
+		}
+		if !slices.ContainsFunc(have, func(value string) bool { return slices.Contains(f.values, value) }) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// tagFilterValues returns the values of the tag:KEY and tag-key filters.
+func tagFilterValues(tags map[string]string, name string) ([]string, bool) {
+	if key, ok := strings.CutPrefix(name, "tag:"); ok {
+		if value, ok := tags[key]; ok {
+			return []string{value}, true
+		}
+		return nil, true
+	}
+	if name == "tag-key" {
+		var keys []string
+		for key := range tags {
+			keys = append(keys, key)
+		}
+		return keys, true
+	}
+	return nil, false
+}
+
+// paginate returns the page of items that NextToken and MaxResults, or the
+// model's PageSize, select, and the token of the next page.
+func paginate[T any](s *Server, q query, items []T) ([]T, string, error) {
+	start := 0
+	if token := q.get("NextToken"); token != "" {
+		var err error
+		start, err = strconv.Atoi(token)
+		if err != nil || start < 0 || start > len(items) {
+			return nil, "", errorf("InvalidPaginationToken", "Invalid pagination token %q", token)
+		}
+	}
+	size := s.model.PageSize
+	if maxResults := q.get("MaxResults"); maxResults != "" {
+		n, err := strconv.Atoi(maxResults)
+		if err != nil || n <= 0 {
+			return nil, "", errorf("InvalidParameterValue", "Invalid MaxResults %q", maxResults)
+		}
+		if size <= 0 || n < size {
+			size = n
+		}
+	}
+	if size <= 0 || start+size >= len(items) {
+		return items[start:], "", nil
+	}
+	return items[start : start+size], strconv.Itoa(start + size), nil
+}
+
+type xmlTag struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+func xmlTags(tags map[string]string) []xmlTag {
+	var out []xmlTag
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		out = append(out, xmlTag{Key: key, Value: tags[key]})
+	}
+	return out
+}
+
+type xmlAddress struct {
+	PublicIP           string   `xml:"publicIp"`
+	AllocationID       string   `xml:"allocationId"`
+	Domain             string   `xml:"domain"`
+	AssociationID      string   `xml:"associationId,omitempty"`
+	NetworkInterfaceID string   `xml:"networkInterfaceId,omitempty"`
+	InstanceID         string   `xml:"instanceId,omitempty"`
+	Tags               []xmlTag `xml:"tagSet>item"`
+}
+
+func (s *Server) describeAddresses(q query) (responder, error) {
+	publicIPs, allocationIDs := q.list("PublicIp"), q.list("AllocationId")
+	for _, ip := range publicIPs {
+		if address := s.model.Address(ip); address == nil || address.PublicIP != ip {
+			return nil, errorf("InvalidAddress.NotFound", "Address '%s' not found.", ip)
+		}
+	}
+	for _, id := range allocationIDs {
+		if address := s.model.Address(id); address == nil || address.AllocationID != id {
+			return nil, errorf("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", id)
+		}
+	}
+
+	var out []xmlAddress
+	for _, address := range s.model.Addresses {
+		if len(publicIPs) > 0 && !slices.Contains(publicIPs, address.PublicIP) {
+			continue
+		}
+		if len(allocationIDs) > 0 && !slices.Contains(allocationIDs, address.AllocationID) {
+			continue
+		}
+		instanceID := s.addressInstanceID(address)
+		ok, err := matchFilters(q.filters(), func(name string) ([]string, bool) {
+			switch name {
+			case "public-ip":
+				return []string{address.PublicIP}, true
+			case "allocation-id":
+				return []string{address.AllocationID}, true
+			case "association-id":
+				return []string{address.AssociationID}, true
+			case "network-interface-id":
+				return []string{address.NetworkInterfaceID}, true
+			case "instance-id":
+				return []string{instanceID}, true
+			case "domain":
+				return []string{"vpc"}, true
+			}
+			return tagFilterValues(address.Tags, name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		out = append(out, xmlAddress{
+			PublicIP:           address.PublicIP,
+			AllocationID:       address.AllocationID,
+			Domain:             "vpc",
+			AssociationID:      address.AssociationID,
+			NetworkInterfaceID: address.NetworkInterfaceID,
+			InstanceID:         instanceID,
+			Tags:               xmlTags(address.Tags),
+		})
+	}
+	return &struct {
+		response
+		Addresses []xmlAddress `xml:"addressesSet>item"`
+	}{response{}, out}, nil
+}
+
+// addressInstanceID returns the instance the address's ENI is attached to.
+func (s *Server) addressInstanceID(address *Address) string {
+	if address.NetworkInterfaceID == "" {
+		return ""
+	}
+	if eni := s.model.NetworkInterface(address.NetworkInterfaceID); eni != nil {
+		return eni.InstanceID
+	}
+	return ""
+}
+
+type xmlNetworkInterface struct {
+	ID         string         `xml:"networkInterfaceId"`
+	SubnetID   string         `xml:"subnetId"`
+	MAC        string         `xml:"macAddress,omitempty"`
+	Status     string         `xml:"status"`
+	Attachment *xmlAttachment `xml:"attachment,omitempty"`
+	IPv6       []xmlIPv6      `xml:"ipv6AddressesSet>item"`
+	Prefixes   []xmlPrefix    `xml:"ipv6PrefixSet>item"`
+	Tags       []xmlTag       `xml:"tagSet>item"`
+}
+
+type xmlAttachment struct {
+	AttachmentID string `xml:"attachmentId"`
+	InstanceID   string `xml:"instanceId"`
+	DeviceIndex  int32  `xml:"deviceIndex"`
+	Status       string `xml:"status"`
+}
+
+type xmlIPv6 struct {
+	Address   string `xml:"ipv6Address"`
+	IsPrimary bool   `xml:"isPrimaryIpv6"`
+}
+
+type xmlPrefix struct {
+	Prefix string `xml:"ipv6Prefix"`
+}
+
+func toXMLNetworkInterface(eni *NetworkInterface) xmlNetworkInterface {
+	out := xmlNetworkInterface{
+		ID:       eni.ID,
+		SubnetID: eni.SubnetID,
+		MAC:      eni.MAC,
+		Status:   "available",
+		Tags:     xmlTags(eni.Tags),
+	}
+	if eni.InstanceID != "" {
+		out.Status = "in-use"
+		out.Attachment = &xmlAttachment{
+			AttachmentID: "eni-attach-" + strings.TrimPrefix(eni.ID, "eni-"),
+			InstanceID:   eni.InstanceID,
+			DeviceIndex:  eni.DeviceIndex,
+			Status:       "attached",
+		}
+	}
+	for i, address := range eni.IPv6Addresses {
+		out.IPv6 = append(out.IPv6, xmlIPv6{Address: address, IsPrimary: eni.PrimaryIPv6 && i == 0})
+	}
+	for _, prefix := range eni.IPv6Prefixes {
+		out.Prefixes = append(out.Prefixes, xmlPrefix{Prefix: prefix})
+	}
+	return out
+}
+
+func (s *Server) describeNetworkInterfaces(q query) (responder, error) {
+	ids := q.list("NetworkInterfaceId")
+	for _, id := range ids {
+		if s.model.NetworkInterface(id) == nil {
+			return nil, errorf("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", id)
+		}
+	}
+
+	var matched []*NetworkInterface
+	for _, eni := range s.model.NetworkInterfaces {
+		if len(ids) > 0 && !slices.Contains(ids, eni.ID) {
+			continue
+		}
+		ok, err := matchFilters(q.filters(), func(name string) ([]string, bool) {
+			switch name {
+			case "network-interface-id":
+				return []string{eni.ID}, true
+			case "subnet-id":
+				return []string{eni.SubnetID}, true
+			case "attachment.instance-id":
+				return []string{eni.InstanceID}, true
+			case "attachment.device-index":
+				if eni.InstanceID == "" {
+					return nil, true
+				}
+				return []string{strconv.Itoa(int(eni.DeviceIndex))}, true
+			case "attachment.status":
+				if eni.InstanceID == "" {
+					return nil, true
+				}
+				return []string{"attached"}, true
+			case "ipv6-addresses.ipv6-address":
+				return eni.IPv6Addresses, true
+			case "status":
+				return []string{toXMLNetworkInterface(eni).Status}, true
+			}
+			return tagFilterValues(eni.Tags, name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, eni)
+		}
+	}
+
+	page, next, err := paginate(s, q, matched)
+	if err != nil {
+		return nil, err
+	}
+	out := &struct {
+		response
+		NetworkInterfaces []xmlNetworkInterface `xml:"networkInterfaceSet>item"`
+		NextToken         string                `xml:"nextToken,omitempty"`
+	}{NextToken: next}
+	for _, eni := range page {
+		out.NetworkInterfaces = append(out.NetworkInterfaces, toXMLNetworkInterface(eni))
+	}
+	return out, nil
+}
+
+func (s *Server) associateAddress(q query) (responder, error) {
+	var address *Address
+	switch {
+	case q.get("AllocationId") != "":
+		address = s.model.Address(q.get("AllocationId"))
+		if address == nil {
+			return nil, errorf("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", q.get("AllocationId"))
+		}
+	case q.get("PublicIp") != "":
+		address = s.model.Address(q.get("PublicIp"))
+		if address == nil {
+			return nil, errorf("InvalidAddress.NotFound", "Address '%s' not found.", q.get("PublicIp"))
+		}
+	default:
+		return nil, errorf("MissingParameter", "Either AllocationId or PublicIp must be specified")
+	}
+
+	var eni *NetworkInterface
+	switch {
+	case q.get("NetworkInterfaceId") != "":
+		eni = s.model.NetworkInterface(q.get("NetworkInterfaceId"))
+		if eni == nil {
+			return nil, errorf("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", q.get("NetworkInterfaceId"))
+		}
+	case q.get("InstanceId") != "":
+		instanceID := q.get("InstanceId")
+		if s.model.Instance(instanceID) == nil {
+			return nil, errorf("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+		}
+		for _, candidate := range s.model.NetworkInterfaces {
+			if candidate.InstanceID == instanceID && candidate.DeviceIndex == 0 {
+				eni = candidate
+			}
+		}
+		if eni == nil {
+			return nil, errorf("InvalidInstanceID", "The instance '%s' has no primary network interface", instanceID)
+		}
+	default:
+		return nil, errorf("MissingParameter", "Either NetworkInterfaceId or InstanceId must be specified")
+	}
+
+	if address.AssociationID != "" && address.NetworkInterfaceID != eni.ID && q.get("AllowReassociation") != "true" {
+		return nil, errorf("Resource.AlreadyAssociated", "resource %s is already associated with associate-id %s", address.AllocationID, address.AssociationID)
+	}
+	address.AssociationID = s.nextID("eipassoc")
+	address.NetworkInterfaceID = eni.ID
+	return &struct {
+		response
+		Return        bool   `xml:"return"`
+		AssociationID string `xml:"associationId"`
+	}{response{}, true, address.AssociationID}, nil
+}
+
+func (s *Server) disassociateAddress(q query) (responder, error) {
+	var address *Address
+	if id := q.get("AssociationId"); id != "" {
+		for _, candidate := range s.model.Addresses {
+			if candidate.AssociationID == id {
+				address = candidate
+			}
+		}
+		if address == nil {
+			return nil, errorf("InvalidAssociationID.NotFound", "The association ID '%s' does not exist", id)
+		}
+	} else {
+		address = s.model.Address(q.get("PublicIp"))
+		if address == nil {
+			return nil, errorf("InvalidAddress.NotFound", "Address '%s' not found.", q.get("PublicIp"))
+		}
+	}
+	address.AssociationID = ""
+	address.NetworkInterfaceID = ""
+	return &struct {
+		response
+		Return bool `xml:"return"`
+	}{response{}, true}, nil
+}
+
+func (s *Server) assignIPv6Addresses(q query) (responder, error) {
+	eni := s.model.NetworkInterface(q.get("NetworkInterfaceId"))
+	if eni == nil {
+		return nil, errorf("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", q.get("NetworkInterfaceId"))
+	}
+	subnet := s.model.Subnet(eni.SubnetID)
+	if subnet == nil || len(subnet.IPv6CIDRs) == 0 {
+		return nil, errorf("InvalidParameterValue", "Subnet %s has no IPv6 CIDR block", eni.SubnetID)
+	}
+
+	addresses := q.list("Ipv6Addresses")
+	if count := q.get("Ipv6AddressCount"); count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return nil, errorf("InvalidParameterValue", "Invalid Ipv6AddressCount %q", count)
+		}
+		for range n {
+			address, err := s.freeIPv6Address(subnet, addresses)
+			if err != nil {
+				return nil, err
+			}
+			addresses = append(addresses, address)
+		}
+	}
+	prefixes := q.list("Ipv6Prefix")
+	if q.get("Ipv6PrefixCount") != "" {
+		return nil, errorf("InvalidParameterValue", "Ipv6PrefixCount is not supported by the fake")
+	}
+
+	var assigned []string
+	for _, value := range addresses {
+		addr, err := netip.ParseAddr(value)
+		if err != nil || !addr.Is6() {
+			return nil, errorf("InvalidParameterValue", "Invalid IPv6 address %s", value)
+		}
+		if !inSubnet(subnet, netip.PrefixFrom(addr, 128)) {
+			return nil, errorf("InvalidParameterValue", "Address %s is not in subnet %s", value, subnet.ID)
+		}
+		if holder := s.model.NetworkInterfaceWithIPv6(addr.String()); holder != nil && holder != eni {
+			return nil, errorf("InvalidParameterValue", "Address %s is in use by %s", value, holder.ID)
+		}
+		assigned = append(assigned, addr.String())
+	}
+	var assignedPrefixes []string
+	for _, value := range prefixes {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil || !prefix.Addr().Is6() || prefix.Bits() != 80 || prefix != prefix.Masked() {
+			return nil, errorf("InvalidParameterValue", "Invalid IPv6 prefix %s", value)
+		}
+		if !inSubnet(subnet, prefix) {
+			return nil, errorf("InvalidParameterValue", "Prefix %s is not in subnet %s", value, subnet.ID)
+		}
+		if holder := s.model.NetworkInterfaceWithIPv6(prefix.String()); holder != nil && holder != eni {
+			return nil, errorf("InvalidParameterValue", "Prefix %s is in use by %s", value, holder.ID)
+		}
+		assignedPrefixes = append(assignedPrefixes, prefix.String())
+	}
+	for _, address := range assigned {
+		if !slices.Contains(eni.IPv6Addresses, address) {
+			eni.IPv6Addresses = append(eni.IPv6Addresses, address)
+		}
+	}
+	for _, prefix := range assignedPrefixes {
+		if !slices.Contains(eni.IPv6Prefixes, prefix) {
+			eni.IPv6Prefixes = append(eni.IPv6Prefixes, prefix)
+		}
+	}
+	return &struct {
+		response
+		Addresses          []string `xml:"assignedIpv6Addresses>item"`
+		Prefixes           []string `xml:"assignedIpv6PrefixSet>item"`
+		NetworkInterfaceID string   `xml:"networkInterfaceId"`
+	}{response{}, assigned, assignedPrefixes, eni.ID}, nil
+}
+
+// freeIPv6Address returns the lowest unassigned address in the subnet's
+// first IPv6 CIDR, skipping the network address and pending.
+func (s *Server) freeIPv6Address(subnet *Subnet, pending []string) (string, error) {
+	cidr, err := netip.ParsePrefix(subnet.IPv6CIDRs[0])
+	if err != nil {
+		return "", err
+	}
+	for addr := cidr.Masked().Addr().Next(); cidr.Contains(addr); addr = addr.Next() {
+		if s.model.NetworkInterfaceWithIPv6(addr.String()) == nil && !slices.Contains(pending, addr.String()) {
+			return addr.String(), nil
+		}
+	}
+	return "", errorf("InsufficientFreeAddressesInSubnet", "Subnet %s has no free IPv6 address", subnet.ID)
+}
+
+func inSubnet(subnet *Subnet, prefix netip.Prefix) bool {
+	for _, value := range subnet.IPv6CIDRs {
+		cidr, err := netip.ParsePrefix(value)
+		if err == nil && cidr.Bits() <= prefix.Bits() && cidr.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) unassignIPv6Addresses(q query) (responder, error) {
+	eni := s.model.NetworkInterface(q.get("NetworkInterfaceId"))
+	if eni == nil {
+		return nil, errorf("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", q.get("NetworkInterfaceId"))
+	}
+	var addresses, prefixes []string
+	for _, value := range q.list("Ipv6Addresses") {
+		value = normalizeIPv6(value)
+		if i := slices.Index(eni.IPv6Addresses, value); i >= 0 {
+			eni.IPv6Addresses = slices.Delete(eni.IPv6Addresses, i, i+1)
+			addresses = append(addresses, value)
+		}
+	}
+	for _, value := range q.list("Ipv6Prefix") {
+		value = normalizeIPv6(value)
+		if i := slices.Index(eni.IPv6Prefixes, value); i >= 0 {
+			eni.IPv6Prefixes = slices.Delete(eni.IPv6Prefixes, i, i+1)
+			prefixes = append(prefixes, value)
+		}
+	}
+	return &struct {
+		response
+		NetworkInterfaceID string   `xml:"networkInterfaceId"`
+		Addresses          []string `xml:"unassignedIpv6Addresses>item"`
+		Prefixes           []string `xml:"unassignedIpv6PrefixSet>item"`
+	}{response{}, eni.ID, addresses, prefixes}, nil
+}
+
+func (s *Server) modifyNetworkInterfaceAttribute(q query) (responder, error) {
+	eni := s.model.NetworkInterface(q.get("NetworkInterfaceId"))
+	if eni == nil {
+		return nil, errorf("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", q.get("NetworkInterfaceId"))
+	}
+	switch q.get("EnablePrimaryIpv6") {
+	case "true":
+		eni.PrimaryIPv6 = true
+	case "":
+		return nil, errorf("InvalidParameterCombination", "Only EnablePrimaryIpv6 is supported by the fake")
+	}
+	return &struct {
+		response
+		Return bool `xml:"return"`
+	}{response{}, true}, nil
+}
+
+type xmlReservation struct {
+	ReservationID string        `xml:"reservationId"`
+	Instances     []xmlInstance `xml:"instancesSet>item"`
+}
+
+type xmlInstance struct {
+	ID    string           `xml:"instanceId"`
+	State xmlInstanceState `xml:"instanceState"`
+	Tags  []xmlTag         `xml:"tagSet>item"`
+}
+
+type xmlInstanceState struct {
+	Code int    `xml:"code"`
+	Name string `xml:"name"`
+}
+
+var instanceStateCodes = map[string]int{"pending": 0, "running": 16, "shutting-down": 32, "terminated": 48, "stopping": 64, "stopped": 80}
+
+func (s *Server) describeInstances(q query) (responder, error) {
+	ids := q.list("InstanceId")
+	for _, id := range ids {
+		if s.model.Instance(id) == nil {
+			return nil, errorf("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", id)
+		}
+	}
+	var matched []*Instance
+	for _, instance := range s.model.Instances {
+		if len(ids) > 0 && !slices.Contains(ids, instance.ID) {
+			continue
+		}
+		ok, err := matchFilters(q.filters(), func(name string) ([]string, bool) {
+			switch name {
+			case "instance-id":
+				return []string{instance.ID}, true
+			case "instance-state-name":
+				return []string{instance.instanceState()}, true
+			}
+			return tagFilterValues(instance.Tags, name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, instance)
+		}
+	}
+
+	page, next, err := paginate(s, q, matched)
+	if err != nil {
+		return nil, err
+	}
+	out := &struct {
+		response
+		Reservations []xmlReservation `xml:"reservationSet>item"`
+		NextToken    string           `xml:"nextToken,omitempty"`
+	}{NextToken: next}
+	for _, instance := range page {
+		state := instance.instanceState()
+		out.Reservations = append(out.Reservations, xmlReservation{
+			ReservationID: "r-" + strings.TrimPrefix(instance.ID, "i-"),
+			Instances: []xmlInstance{{
+				ID:    instance.ID,
+				State: xmlInstanceState{Code: instanceStateCodes[state], Name: state},
+				Tags:  xmlTags(instance.Tags),
+			}},
+		})
+	}
+	return out, nil
+}
+
+type xmlSubnet struct {
+	ID   string          `xml:"subnetId"`
+	IPv6 []xmlSubnetIPv6 `xml:"ipv6CidrBlockAssociationSet>item"`
+}
+
+type xmlSubnetIPv6 struct {
+	AssociationID string       `xml:"associationId"`
+	CIDR          string       `xml:"ipv6CidrBlock"`
+	State         xmlCIDRState `xml:"ipv6CidrBlockState"`
+}
+
+type xmlCIDRState struct {
+	State string `xml:"state"`
+}
+
+func (s *Server) describeSubnets(q query) (responder, error) {
+	ids := q.list("SubnetId")
+	for _, id := range ids {
+		if s.model.Subnet(id) == nil {
+			return nil, errorf("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", id)
+		}
+	}
+	var matched []*Subnet
+	for _, subnet := range s.model.Subnets {
+		if len(ids) > 0 && !slices.Contains(ids, subnet.ID) {
+			continue
+		}
+		ok, err := matchFilters(q.filters(), func(name string) ([]string, bool) {
+			if name == "subnet-id" {
+				return []string{subnet.ID}, true
+			}
+			return nil, false
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, subnet)
+		}
+	}
+
+	page, next, err := paginate(s, q, matched)
+	if err != nil {
+		return nil, err
+	}
+	out := &struct {
+		response
+		Subnets   []xmlSubnet `xml:"subnetSet>item"`
+		NextToken string      `xml:"nextToken,omitempty"`
+	}{NextToken: next}
+	for _, subnet := range page {
+		item := xmlSubnet{ID: subnet.ID}
+		for i, cidr := range subnet.IPv6CIDRs {
+			item.IPv6 = append(item.IPv6, xmlSubnetIPv6{
+				AssociationID: fmt.Sprintf("subnet-cidr-assoc-%s-%d", strings.TrimPrefix(subnet.ID, "subnet-"), i),
+				CIDR:          cidr,
+				State:         xmlCIDRState{State: "associated"},
+			})
+		}
+		out.Subnets = append(out.Subnets, item)
+	}
+	return out, nil
+}
+
+type xmlTagDescription struct {
+	ResourceID   string `xml:"resourceId"`
+	ResourceType string `xml:"resourceType"`
+	Key          string `xml:"key"`
+	Value        string `xml:"value"`
+}
+
+func (s *Server) describeTags(q query) (responder, error) {
+	var all []xmlTagDescription
+	add := func(resourceID, resourceType string, tags map[string]string) {
+		for _, tag := range xmlTags(tags) {
+			all = append(all, xmlTagDescription{ResourceID: resourceID, ResourceType: resourceType, Key: tag.Key, Value: tag.Value})
+		}
+	}
+	for _, instance := range s.model.Instances {
+		add(instance.ID, "instance", instance.Tags)
+	}
+	for _, eni := range s.model.NetworkInterfaces {
+		add(eni.ID, "network-interface", eni.Tags)
+	}
+	for _, address := range s.model.Addresses {
+		add(address.AllocationID, "elastic-ip", address.Tags)
+	}
+
+	var matched []xmlTagDescription
+	for _, tag := range all {
+		ok, err := matchFilters(q.filters(), func(name string) ([]string, bool) {
+			switch name {
+			case "resource-id":
+				return []string{tag.ResourceID}, true
+			case "resource-type":
+				return []string{tag.ResourceType}, true
+			case "key":
+				return []string{tag.Key}, true
+			case "value":
+				return []string{tag.Value}, true
+			}
+			return nil, false
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, tag)
+		}
+	}
+
+	page, next, err := paginate(s, q, matched)
+	if err != nil {
+		return nil, err
+	}
+	return &struct {
+		response
+		Tags      []xmlTagDescription `xml:"tagSet>item"`
+		NextToken string              `xml:"nextToken,omitempty"`
+	}{response{}, page, next}, nil
+}
@@ -0,0 +1,91 @@
+package fakeaws
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	imdsTokenPath   = "/latest/api/token"
+	imdsMetadataDir = "/latest/meta-data/"
+	imdsTokenTTL    = "X-aws-ec2-metadata-token-ttl-seconds"
+	imdsToken       = "X-aws-ec2-metadata-token"
+)
+
+// serveIMDS handles the IMDSv2 protocol: a PUT for a session token, then
+// metadata GETs that carry it.
+func (s *Server) serveIMDS(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == imdsTokenPath {
+		if r.Method != http.MethodPut {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ttl := r.Header.Get(imdsTokenTTL)
+		if ttl == "" {
+			http.Error(w, "missing "+imdsTokenTTL, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(imdsTokenTTL, ttl)
+		_, _ = w.Write([]byte(s.token))
+		return
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, imdsMetadataDir)
+	if !ok || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get(imdsToken) != s.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, "GetMetadata:"+path)
+	value, ok := s.metadata()[path]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(value))
+}
+
+// metadata returns the instance metadata paths for the model's instance,
+// relative to /latest/meta-data/.
+func (s *Server) metadata() map[string]string {
+	m := s.model
+	paths := map[string]string{
+		"instance-id":      m.InstanceID,
+		"placement/region": m.Region,
+	}
+	if instance := m.Instance(m.InstanceID); instance != nil {
+		var keys []string
+		for key, value := range instance.Tags {
+			paths["tags/instance/"+key] = value
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		paths["tags/instance"] = strings.Join(keys, "\n")
+	}
+	var macs []string
+	for _, eni := range m.NetworkInterfaces {
+		if eni.InstanceID != m.InstanceID || eni.MAC == "" {
+			continue
+		}
+		macs = append(macs, eni.MAC+"/")
+		paths["network/interfaces/macs/"+eni.MAC+"/interface-id"] = eni.ID
+		paths["network/interfaces/macs/"+eni.MAC+"/subnet-id"] = eni.SubnetID
+		if len(eni.IPv6Addresses) > 0 {
+			paths["network/interfaces/macs/"+eni.MAC+"/ipv6s"] = strings.Join(eni.IPv6Addresses, "\n")
+		}
+	}
+	if len(macs) > 0 {
+		paths["network/interfaces/macs/"] = strings.Join(macs, "\n")
+	}
+	maps.Copy(paths, m.Metadata)
+	return paths
+}
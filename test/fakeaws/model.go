@@ -0,0 +1,178 @@
+// Package fakeaws is an in-memory stand-in for the EC2 Query API subset in
+// eip.EC2API and for the IMDSv2 instance metadata service, so the real
+// binary can run end to end without an AWS account.
+//
+// A Server serves both on one listener. Point the binary at it with
+// AWS_ENDPOINT_URL_EC2 and AWS_EC2_METADATA_SERVICE_ENDPOINT set to the
+// server URL, and with static AWS credentials in the environment.
+package fakeaws
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+)
+
+// Model is the state the fake serves. Its JSON form is the -state file of
+// the fakeaws command and the body of the /_fakeaws/state endpoint.
+type Model struct {
+	Region string `json:"region"`
+	// InstanceID is the instance instance metadata describes.
+	InstanceID        string              `json:"instance_id"`
+	Instances         []*Instance         `json:"instances,omitempty"`
+	Subnets           []*Subnet           `json:"subnets,omitempty"`
+	NetworkInterfaces []*NetworkInterface `json:"network_interfaces,omitempty"`
+	Addresses         []*Address          `json:"addresses,omitempty"`
+	// Metadata adds or overrides instance metadata paths, for example
+	// "spot/instance-action".
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// PageSize, when positive, caps the results of each page of the
+	// paginated Describe calls, to exercise pagination.
+	PageSize int `json:"page_size,omitempty"`
+}
+
+// Instance is an EC2 instance.
+type Instance struct {
+	ID string `json:"id"`
+	// State defaults to "running".
+	State string            `json:"state,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+// Subnet is a VPC subnet.
+type Subnet struct {
+	ID        string   `json:"id"`
+	IPv6CIDRs []string `json:"ipv6_cidrs,omitempty"`
+}
+
+// NetworkInterface is an ENI, attached when InstanceID is set.
+type NetworkInterface struct {
+	ID          string `json:"id"`
+	SubnetID    string `json:"subnet_id"`
+	InstanceID  string `json:"instance_id,omitempty"`
+	DeviceIndex int32  `json:"device_index"`
+	MAC         string `json:"mac,omitempty"`
+	// IPv6Addresses are the assigned IPv6 addresses. With PrimaryIPv6 the
+	// first one is the primary IPv6 address.
+	IPv6Addresses []string          `json:"ipv6_addresses,omitempty"`
+	IPv6Prefixes  []string          `json:"ipv6_prefixes,omitempty"`
+	PrimaryIPv6   bool              `json:"primary_ipv6,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+// Address is an Elastic IP, associated when AssociationID is set.
+type Address struct {
+	AllocationID       string            `json:"allocation_id"`
+	PublicIP           string            `json:"public_ip"`
+	AssociationID      string            `json:"association_id,omitempty"`
+	NetworkInterfaceID string            `json:"network_interface_id,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+}
+
+// LoadModel reads a Model from a JSON file.
+func LoadModel(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var model Model
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return &model, nil
+}
+
+// Clone returns a deep copy of m.
+func (m *Model) Clone() *Model {
+	clone := *m
+	clone.Metadata = maps.Clone(m.Metadata)
+	clone.Instances = nil
+	for _, instance := range m.Instances {
+		copied := *instance
+		copied.Tags = maps.Clone(instance.Tags)
+		clone.Instances = append(clone.Instances, &copied)
+	}
+	clone.Subnets = nil
+	for _, subnet := range m.Subnets {
+		copied := *subnet
+		copied.IPv6CIDRs = slices.Clone(subnet.IPv6CIDRs)
+		clone.Subnets = append(clone.Subnets, &copied)
+	}
+	clone.NetworkInterfaces = nil
+	for _, eni := range m.NetworkInterfaces {
+		copied := *eni
+		copied.IPv6Addresses = slices.Clone(eni.IPv6Addresses)
+		copied.IPv6Prefixes = slices.Clone(eni.IPv6Prefixes)
+		copied.Tags = maps.Clone(eni.Tags)
+		clone.NetworkInterfaces = append(clone.NetworkInterfaces, &copied)
+	}
+	clone.Addresses = nil
+	for _, address := range m.Addresses {
+		copied := *address
+		copied.Tags = maps.Clone(address.Tags)
+		clone.Addresses = append(clone.Addresses, &copied)
+	}
+	return &clone
+}
+
+// Instance returns the instance with id, or nil.
+func (m *Model) Instance(id string) *Instance {
+	for _, instance := range m.Instances {
+		if instance.ID == id {
+			return instance
+		}
+	}
+	return nil
+}
+
+// Subnet returns the subnet with id, or nil.
+func (m *Model) Subnet(id string) *Subnet {
+	for _, subnet := range m.Subnets {
+		if subnet.ID == id {
+			return subnet
+		}
+	}
+	return nil
+}
+
+// NetworkInterface returns the ENI with id, or nil.
+func (m *Model) NetworkInterface(id string) *NetworkInterface {
+	for _, eni := range m.NetworkInterfaces {
+		if eni.ID == id {
+			return eni
+		}
+	}
+	return nil
+}
+
+// Address returns the Elastic IP with the public IP or allocation ID, or
+// nil.
+func (m *Model) Address(publicIPOrAllocationID string) *Address {
+	for _, address := range m.Addresses {
+		if address.PublicIP == publicIPOrAllocationID || address.AllocationID == publicIPOrAllocationID {
+			return address
+		}
+	}
+	return nil
+}
+
+// NetworkInterfaceWithIPv6 returns the ENI holding the IPv6 address or
+// prefix, or nil.
+func (m *Model) NetworkInterfaceWithIPv6(addressOrPrefix string) *NetworkInterface {
+	for _, eni := range m.NetworkInterfaces {
+		if slices.Contains(eni.IPv6Addresses, addressOrPrefix) || slices.Contains(eni.IPv6Prefixes, addressOrPrefix) {
+			return eni
+		}
+	}
+	return nil
+}
+
+// instanceState returns the state of instance, defaulting to running.
+func (i *Instance) instanceState() string {
+	if i.State == "" {
+		return "running"
+	}
+	return i.State
+}
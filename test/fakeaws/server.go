@@ -0,0 +1,127 @@
+package fakeaws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StatePath is where a Server serves its model: GET returns it as JSON and
+// PUT replaces it, so scenario scripts can arrange and inspect state while
+// the binary runs.
+const StatePath = "/_fakeaws/state"
+
+// Server serves the fake EC2 Query API and instance metadata service from
+// one Model. Instance metadata is under /latest/; every other path is EC2.
+type Server struct {
+	mu       sync.Mutex
+	model    *Model
+	token    string
+	requests int
+	ids      int
+	calls    []string
+}
+
+// NewServer returns a Server backed by a copy of model.
+func NewServer(model *Model) *Server {
+	s := &Server{token: "fakeaws-token"}
+	s.SetModel(model)
+	return s
+}
+
+// Model returns a copy of the current state.
+func (s *Server) Model() *Model {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.model.Clone()
+}
+
+// SetModel replaces the state with a copy of model. IPv6 addresses and
+// prefixes are stored in canonical form so lookups match however the
+// caller wrote them.
+func (s *Server) SetModel(model *Model) {
+	model = model.Clone()
+	for _, eni := range model.NetworkInterfaces {
+		for i, address := range eni.IPv6Addresses {
+			eni.IPv6Addresses[i] = normalizeIPv6(address)
+		}
+		for i, prefix := range eni.IPv6Prefixes {
+			eni.IPv6Prefixes[i] = normalizeIPv6(prefix)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.model = model
+}
+
+// Calls returns the EC2 actions and metadata paths requested so far, in
+// order. Metadata paths are prefixed with "GetMetadata:".
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+// ServeHTTP routes the request to instance metadata, the state endpoint, or
+// EC2.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/latest/"):
+		s.serveIMDS(w, r)
+	case r.URL.Path == StatePath:
+		s.serveState(w, r)
+	default:
+		s.serveEC2(w, r)
+	}
+}
+
+func (s *Server) serveState(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(s.Model())
+	case http.MethodPut:
+		var model Model
+		if err := json.NewDecoder(r.Body).Decode(&model); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.SetModel(&model)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// nextRequestID returns a new AWS-style request ID.
+func (s *Server) nextRequestID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012x", s.requests)
+}
+
+// nextID returns a new resource ID such as eipassoc-00000000000000001. The
+// caller holds s.mu.
+func (s *Server) nextID(prefix string) string {
+	s.ids++
+	return fmt.Sprintf("%s-%017x", prefix, s.ids)
+}
+
+// normalizeIPv6 returns the canonical form of an IPv6 address or prefix, or
+// value unchanged when it is neither.
+func normalizeIPv6(value string) string {
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix.String()
+	}
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.String()
+	}
+	return value
+}
@@ -0,0 +1,244 @@
+package fakeaws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+
+	"github.com/islishude/aws-eip-binding/eip"
+)
+
+// testModel has instance i-local with ENI eni-local, and instance i-other
+// holding 54.162.153.80 and 2001:db8::10 on eni-other.
+func testModel() *Model {
+	return &Model{
+		Region:     "us-east-1",
+		InstanceID: "i-local",
+		Instances: []*Instance{
+			{ID: "i-local", Tags: map[string]string{"Name": "local"}},
+			{ID: "i-other"},
+		},
+		Subnets: []*Subnet{{ID: "subnet-1", IPv6CIDRs: []string{"2001:db8::/64"}}},
+		NetworkInterfaces: []*NetworkInterface{
+			{ID: "eni-local", SubnetID: "subnet-1", InstanceID: "i-local", MAC: "0a:00:00:00:00:01"},
+			{ID: "eni-other", SubnetID: "subnet-1", InstanceID: "i-other", MAC: "0a:00:00:00:00:02", IPv6Addresses: []string{"2001:DB8::10"}},
+		},
+		Addresses: []*Address{
+			{AllocationID: "eipalloc-1", PublicIP: "54.162.153.80", AssociationID: "eipassoc-old", NetworkInterfaceID: "eni-other"},
+		},
+	}
+}
+
+// newTestBinder returns a Binder using the real SDK clients against server.
+func newTestBinder(t *testing.T, server *Server) *eip.Binder {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	ec2Client := ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: new(httpServer.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	imds := ec2imds.New(ec2imds.Options{Endpoint: httpServer.URL})
+	return eip.NewBinder(ec2Client, imds, log.New(io.Discard, "", 0))
+}
+
+func TestBindIPv4MovesAddress(t *testing.T) {
+	server := NewServer(testModel())
+	result, err := newTestBinder(t, server).Bind(context.Background(), "54.162.153.80")
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if result.NetworkInterfaceID != "eni-local" || result.InstanceID != "i-local" || result.PreviousInstanceID != "i-other" {
+		t.Fatalf("result = %+v, want move from i-other to eni-local on i-local", result)
+	}
+
+	address := server.Model().Address("54.162.153.80")
+	if address.NetworkInterfaceID != "eni-local" || address.AssociationID != result.AssociationID || address.AssociationID == "eipassoc-old" {
+		t.Fatalf("address = %+v, want new association on eni-local", address)
+	}
+}
+
+func TestBindIPv6MovesAddress(t *testing.T) {
+	server := NewServer(testModel())
+	result, err := newTestBinder(t, server).Bind(context.Background(), "2001:db8::10")
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if result.NetworkInterfaceID != "eni-local" || result.PreviousNetworkInterfaceID != "eni-other" {
+		t.Fatalf("result = %+v, want move from eni-other to eni-local", result)
+	}
+
+	model := server.Model()
+	if got := model.NetworkInterface("eni-local").IPv6Addresses; !slices.Equal(got, []string{"2001:db8::10"}) {
+		t.Fatalf("eni-local IPv6 = %v, want [2001:db8::10]", got)
+	}
+	if got := model.NetworkInterface("eni-other").IPv6Addresses; len(got) != 0 {
+		t.Fatalf("eni-other IPv6 = %v, want none", got)
+	}
+}
+
+func TestBindPaginatesNetworkInterfaces(t *testing.T) {
+	model := testModel()
+	model.PageSize = 1
+	model.NetworkInterfaces = append([]*NetworkInterface{
+		{ID: "eni-spare", SubnetID: "subnet-1", InstanceID: "i-local", DeviceIndex: 1},
+	}, model.NetworkInterfaces...)
+	server := NewServer(model)
+
+	result, err := newTestBinder(t, server).Bind(context.Background(), "54.162.153.80")
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if result.NetworkInterfaceID != "eni-local" {
+		t.Fatalf("ENI = %s, want eni-local", result.NetworkInterfaceID)
+	}
+}
+
+func TestEC2Errors(t *testing.T) {
+	server := NewServer(testModel())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client := ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: new(httpServer.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+
+	tests := []struct {
+		name string
+		call func() error
+		code string
+	}{
+		{
+			name: "unknown address",
+			call: func() error {
+				_, err := client.DescribeAddresses(context.Background(), &ec2.DescribeAddressesInput{PublicIps: []string{"192.0.2.1"}})
+				return err
+			},
+			code: "InvalidAddress.NotFound",
+		},
+		{
+			name: "reassociation not allowed",
+			call: func() error {
+				_, err := client.AssociateAddress(context.Background(), &ec2.AssociateAddressInput{
+					AllocationId:       new("eipalloc-1"),
+					NetworkInterfaceId: new("eni-local"),
+				})
+				return err
+			},
+			code: "Resource.AlreadyAssociated",
+		},
+		{
+			name: "dry run",
+			call: func() error {
+				_, err := client.DisassociateAddress(context.Background(), &ec2.DisassociateAddressInput{
+					AssociationId: new("eipassoc-old"),
+					DryRun:        new(true),
+				})
+				return err
+			},
+			code: "DryRunOperation",
+		},
+		{
+			name: "address outside subnet",
+			call: func() error {
+				_, err := client.AssignIpv6Addresses(context.Background(), &ec2.AssignIpv6AddressesInput{
+					NetworkInterfaceId: new("eni-local"),
+					Ipv6Addresses:      []string{"2001:db9::1"},
+				})
+				return err
+			},
+			code: "InvalidParameterValue",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			apiErr, ok := errors.AsType[smithy.APIError](err)
+			if !ok || apiErr.ErrorCode() != tt.code {
+				t.Fatalf("error = %v, want code %s", err, tt.code)
+			}
+		})
+	}
+	if address := server.Model().Address("eipalloc-1"); address.AssociationID != "eipassoc-old" {
+		t.Fatalf("address = %+v, want it unchanged", address)
+	}
+}
+
+func TestIMDSRequiresToken(t *testing.T) {
+	httpServer := httptest.NewServer(NewServer(testModel()))
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/latest/meta-data/instance-id")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", resp.StatusCode)
+	}
+
+	imds := ec2imds.New(ec2imds.Options{Endpoint: httpServer.URL})
+	for path, want := range map[string]string{
+		"instance-id":        "i-local",
+		"tags/instance/Name": "local",
+		"network/interfaces/macs/0a:00:00:00:00:01/interface-id": "eni-local",
+	} {
+		out, err := imds.GetMetadata(context.Background(), &ec2imds.GetMetadataInput{Path: path})
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		got, _ := io.ReadAll(out.Content)
+		out.Content.Close()
+		if string(got) != want {
+			t.Fatalf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestStateEndpoint(t *testing.T) {
+	server := NewServer(&Model{})
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	body, err := json.Marshal(testModel())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, httpServer.URL+StatePath, bytes.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("put status = %d, want 204", resp.StatusCode)
+	}
+
+	resp, err = http.Get(httpServer.URL + StatePath)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	var model Model
+	if err := json.NewDecoder(resp.Body).Decode(&model); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if model.InstanceID != "i-local" || model.NetworkInterface("eni-other").IPv6Addresses[0] != "2001:db8::10" {
+		t.Fatalf("state = %+v, want the stored model with canonical IPv6", model)
+	}
+}